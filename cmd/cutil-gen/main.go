@@ -0,0 +1,71 @@
+// cutil-gen 根据 CREATE TABLE 语句生成模型结构体、列名常量与表描述
+//
+// 用法:
+//
+//	cutil-gen -dialect mysql -pkg model -out ./model schema.sql [more.sql ...]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Cooooing/cutil/base/str"
+	"github.com/Cooooing/cutil/query/base"
+	"github.com/Cooooing/cutil/query/gen"
+)
+
+func main() {
+	var (
+		dialect = flag.String("dialect", string(base.DialectMySQL), "database dialect: mysql or postgres")
+		pkg     = flag.String("pkg", "model", "package name of generated code")
+		out     = flag.String("out", ".", "output directory")
+	)
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: cutil-gen [flags] ddl.sql [ddl.sql ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(base.Dialect(*dialect), *pkg, *out, flag.Args()); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "cutil-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dialect base.Dialect, pkg string, out string, files []string) error {
+	if dialect != base.DialectMySQL && dialect != base.DialectPostgres {
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		ddl, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		tables, err := gen.ParseDDL(dialect, string(ddl))
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", file, err)
+		}
+		// 每张表生成一个文件
+		for _, table := range tables {
+			src, err := gen.Generate(pkg, dialect, []*gen.Table{table})
+			if err != nil {
+				return fmt.Errorf("generate %s: %w", table.Name, err)
+			}
+			path := filepath.Join(out, str.ToSnakeCase(table.Name)+".go")
+			if err := os.WriteFile(path, src, 0o644); err != nil {
+				return err
+			}
+			fmt.Println(path)
+		}
+	}
+	return nil
+}
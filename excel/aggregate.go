@@ -0,0 +1,207 @@
+package excel
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Aggregate 列汇总方式，汇总行写入 SUBTOTAL 公式，合计时忽略范围内的小计行
+type Aggregate int
+
+const (
+	AggregateNone  Aggregate = iota // 不汇总
+	AggregateSum                    // 求和
+	AggregateAvg                    // 平均值
+	AggregateCount                  // 非空单元格计数
+	AggregateMin                    // 最小值
+	AggregateMax                    // 最大值
+)
+
+const (
+	defaultTotalLabel    = "合计"
+	defaultSubtotalLabel = "小计"
+)
+
+// function 返回 SUBTOTAL 的函数编号
+func (a Aggregate) function() int {
+	switch a {
+	case AggregateSum:
+		return 9
+	case AggregateAvg:
+		return 1
+	case AggregateCount:
+		return 3
+	case AggregateMin:
+		return 5
+	case AggregateMax:
+		return 4
+	}
+	return 0
+}
+
+// summary 工作表的汇总行：末尾的合计行与分组键变化时的小计行
+type summary struct {
+	aggregates []Aggregate // 各列汇总方式
+	styles     []int       // 汇总行样式
+	groupIndex int         // 分组列索引，-1 为不分组
+	groupStart int         // 当前分组的起始行，0 为未开始
+	groupValue string      // 当前分组的值
+}
+
+// newSummary 按列选项创建汇总行定义，没有汇总列时返回 nil
+//
+// 参数:
+//   - l: 列布局
+//   - columnStyles: 各列数据行的样式定义
+//
+// 返回:
+//   - *summary: 汇总行定义
+//   - error: 汇总选项错误或创建样式失败的错误信息
+func (f *File) newSummary(l *layout, columnStyles []cellStyle) (*summary, error) {
+	s := &summary{aggregates: make([]Aggregate, len(l.keys)), styles: make([]int, len(l.keys)), groupIndex: -1}
+	enabled := false
+	for i, key := range l.keys {
+		col := l.opts.Columns[key]
+		if col.Aggregate < AggregateNone || col.Aggregate > AggregateMax {
+			return nil, fmt.Errorf("column %s has invalid aggregate %d", key, col.Aggregate)
+		}
+		s.aggregates[i] = col.Aggregate
+		enabled = enabled || col.Aggregate != AggregateNone
+	}
+	if !enabled {
+		if l.opts.GroupBy != "" {
+			return nil, fmt.Errorf("group by %s requires aggregate columns", l.opts.GroupBy)
+		}
+		return nil, nil
+	}
+	if l.opts.GroupBy != "" {
+		if s.groupIndex = slices.Index(l.keys, l.opts.GroupBy); s.groupIndex == -1 {
+			return nil, fmt.Errorf("group by key %s not exists", l.opts.GroupBy)
+		}
+	}
+	// 汇总行沿用列的格式与对齐，字体加粗
+	for i, cs := range columnStyles {
+		cs.bold = true
+		var err error
+		if s.styles[i], err = f.styleFor(cs); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// reserved 返回每个数据行之外可能追加的汇总行数，用于工作表滚动时预留行
+func (s *summary) reserved() int {
+	if s == nil {
+		return 0
+	}
+	if s.groupIndex >= 0 {
+		// 写入数据行前的小计行，以及结束工作表时的小计行与合计行
+		return 3
+	}
+	return 1
+}
+
+// writeSubtotal 在 rowIndex 写入当前分组的小计行，小计行同时结束相同值的合并区域
+func (f *File) writeSubtotal(sheetName string, l *layout, rowIndex int, sw *excelize.StreamWriter) error {
+	s := l.summary
+	if err := f.mergeCells(sheetName, l.flushMerges(rowIndex-1), sw); err != nil {
+		return err
+	}
+	label := l.opts.SubtotalLabel
+	if label == "" {
+		label = defaultSubtotalLabel
+	}
+	if s.groupValue != "" {
+		label = s.groupValue + " " + label
+	}
+	err := f.writeSummaryRow(sheetName, l, rowIndex, s.groupIndex, label, s.groupStart, rowIndex-1, sw)
+	s.groupStart, s.groupValue = 0, ""
+	return err
+}
+
+// writeSummary 结束工作表时写入最后一个分组的小计行与合计行
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - l: 列布局
+//   - lastRow: 最后一个数据行的行号
+//   - sw: 流式写入器
+//
+// 返回:
+//   - int: 合计行之前的最后一行（数据行或小计行）的行号
+//   - error: 写入失败的错误信息
+func (f *File) writeSummary(sheetName string, l *layout, lastRow int, sw *excelize.StreamWriter) (int, error) {
+	s := l.summary
+	if s == nil || f.writer != nil || lastRow <= l.headerRows() {
+		return lastRow, nil
+	}
+	if s.groupStart != 0 {
+		lastRow++
+		if err := f.writeSubtotal(sheetName, l, lastRow, sw); err != nil {
+			return 0, err
+		}
+	}
+	labelIndex := slices.Index(s.aggregates, AggregateNone)
+	label := l.opts.TotalLabel
+	if label == "" {
+		label = defaultTotalLabel
+	}
+	return lastRow, f.writeSummaryRow(sheetName, l, lastRow+1, labelIndex, label, l.headerRows()+1, lastRow, sw)
+}
+
+// writeSummaryRow 写入汇总行：汇总列为 SUBTOTAL 公式，标签写入 labelIndex 列
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - l: 列布局
+//   - rowIndex: 写入的行号
+//   - labelIndex: 标签所在列索引，-1 为不写入标签
+//   - label: 标签
+//   - firstRow: 汇总范围的起始行
+//   - lastRow: 汇总范围的结束行
+//   - sw: 流式写入器
+//
+// 返回:
+//   - error: 写入失败的错误信息
+func (f *File) writeSummaryRow(sheetName string, l *layout, rowIndex int, labelIndex int, label string, firstRow int, lastRow int, sw *excelize.StreamWriter) error {
+	s := l.summary
+	cells := make([]any, len(l.keys))
+	for i, aggregate := range s.aggregates {
+		cell := excelize.Cell{StyleID: s.styles[i]}
+		if aggregate != AggregateNone {
+			col, _ := excelize.ColumnNumberToName(i + 1)
+			cell.Formula = fmt.Sprintf("SUBTOTAL(%d,%s%d:%s%d)", aggregate.function(), col, firstRow, col, lastRow)
+		} else if i == labelIndex {
+			cell.Value = label
+		}
+		cells[i] = cell
+	}
+	cellAddr, _ := excelize.CoordinatesToCellName(1, rowIndex)
+	if sw != nil {
+		return sw.SetRow(cellAddr, cells)
+	}
+
+	for i, value := range cells {
+		cell := value.(excelize.Cell)
+		cellAddr, _ := excelize.CoordinatesToCellName(i+1, rowIndex)
+		var err error
+		switch {
+		case cell.Formula != "":
+			err = f.file.SetCellFormula(sheetName, cellAddr, cell.Formula)
+		case cell.Value != nil:
+			err = f.file.SetCellValue(sheetName, cellAddr, cell.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("write summary row %d failed: %w", rowIndex, err)
+		}
+		if cell.StyleID > 0 {
+			if err := f.file.SetCellStyle(sheetName, cellAddr, cellAddr, cell.StyleID); err != nil {
+				return fmt.Errorf("set summary style failed: %w", err)
+			}
+		}
+	}
+	return nil
+}
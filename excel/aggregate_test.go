@@ -0,0 +1,89 @@
+package excel
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestAggregate(t *testing.T) {
+	titles := []string{"地区", "城市", "金额", "单价"}
+	keys := []string{"region", "city", "amount", "price"}
+	data := []map[string]any{
+		{"region": "华东", "city": "上海", "amount": 10, "price": 1},
+		{"region": "华东", "city": "杭州", "amount": 20, "price": 3},
+		{"region": "华北", "city": "北京", "amount": 30, "price": 5},
+	}
+	opts := SheetOptions{
+		Columns: map[string]ColumnOptions{
+			"city":   {Aggregate: AggregateCount},
+			"amount": {Aggregate: AggregateSum, Format: "#,##0"},
+			"price":  {Aggregate: AggregateMax},
+		},
+		GroupBy: "region",
+	}
+
+	src := NewFile("aggregate.xlsx")
+	for _, stream := range []bool{false, true} {
+		sheet := fmt.Sprintf("stream_%v", stream)
+		src.SetSheetOptions(sheet, opts)
+		var err error
+		if stream {
+			err = src.ExportStreamFromDataMap(sheet, titles, keys, data)
+		} else {
+			err = src.ExportFromDataMap(sheet, titles, keys, data)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := src.GetExcelizeFile().WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, stream := range []bool{false, true} {
+		sheet := fmt.Sprintf("stream_%v", stream)
+		t.Run(sheet, func(t *testing.T) {
+			// 2-3 华东，4 华东小计，5 华北，6 华北小计，7 合计
+			cells := map[string]string{
+				"A4": "华东 小计",
+				"A6": "华北 小计",
+				"A7": "合计",
+				"B5": "北京",
+			}
+			for cell, want := range cells {
+				if value, _ := f.GetCellValue(sheet, cell); value != want {
+					t.Errorf("expected %s to be %q, got %q", cell, want, value)
+				}
+			}
+			formulas := map[string]string{
+				"C4": "SUBTOTAL(9,C2:C3)",
+				"C6": "SUBTOTAL(9,C5:C5)",
+				"C7": "SUBTOTAL(9,C2:C6)",
+				"B7": "SUBTOTAL(3,B2:B6)",
+				"D7": "SUBTOTAL(4,D2:D6)",
+			}
+			for cell, want := range formulas {
+				if formula, _ := f.GetCellFormula(sheet, cell); formula != want {
+					t.Errorf("expected %s formula %q, got %q", cell, want, formula)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateGroupByWithoutAggregate(t *testing.T) {
+	f := NewFile("aggregate.xlsx")
+	f.SetSheetOptions("Sheet1", SheetOptions{GroupBy: "id"})
+	err := f.ExportFromDataMap("Sheet1", []string{"编号"}, []string{"id"}, []map[string]any{{"id": 1}})
+	if err == nil {
+		t.Fatal("expected error for group by without aggregate columns")
+	}
+}
@@ -0,0 +1,346 @@
+package excel
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cooooing/cutil/base/logger"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// ErrUnsupported 当前输出格式不支持该操作，如 csv 不支持随机写入单元格
+var ErrUnsupported = errors.New("operation not supported by writer")
+
+// CSVSheetName 读取 csv 时数据所在的工作表名称
+const CSVSheetName = "Sheet1"
+
+const utf8BOM = "\xEF\xBB\xBF"
+
+// rowWriter 行写入目标，替代 xlsx 工作簿承接导出数据。导出函数的标题处理与校验逻辑不变，
+// 样式、批注、列宽等 xlsx 特有的展示信息不会写入
+type rowWriter interface {
+	// WriteRow 写入一行，同一工作表的行号从 1 开始递增
+	WriteRow(sheetName string, rowIndex int, values []any) error
+	// WriteTo 输出全部数据
+	WriteTo(w io.Writer) (int64, error)
+	// Close 释放写入时占用的资源
+	Close() error
+}
+
+// QuoteMode 引号模式
+type QuoteMode int
+
+const (
+	QuoteMinimal QuoteMode = iota // 仅在字段包含分隔符、引号、换行或首尾空白时加引号
+	QuoteAll                      // 所有字段加引号
+	QuoteNone                     // 不加引号，字段中的分隔符与换行替换为空格
+)
+
+// CSVOptions csv/tsv 读写选项
+type CSVOptions struct {
+	Delimiter rune              // 分隔符，默认为逗号，tsv 为制表符
+	Quote     QuoteMode         // 引号模式
+	CRLF      bool              // 使用 \r\n 换行
+	BOM       bool              // 写入 UTF-8 BOM，便于 Excel 识别编码，仅在未指定 Encoding 时生效
+	Encoding  encoding.Encoding // 字符编码，如 simplifiedchinese.GBK，默认 UTF-8
+}
+
+// csvWriter 将各工作表写入独立的临时文件，输出时按编码转换。单个工作表直接输出，多个工作表打包为 zip
+type csvWriter struct {
+	opts   CSVOptions
+	sheets []*csvSheet
+	index  map[string]*csvSheet
+}
+
+type csvSheet struct {
+	name    string
+	file    *os.File
+	buf     *bufio.Writer
+	lastRow int
+}
+
+// NewCSVFile 创建输出 csv 的文件对象，导出函数与 xlsx 一致。导出多个工作表时输出为 zip，每个工作表一个文件
+//
+// 参数:
+//   - fileName: 文件名
+//   - opts: csv 选项
+//
+// 返回:
+//   - *File: 文件对象
+func NewCSVFile(fileName string, opts CSVOptions) *File {
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	f := NewFile(fileName)
+	f.writer = &csvWriter{opts: opts, index: make(map[string]*csvSheet)}
+	return f
+}
+
+// NewTSVFile 创建输出 tsv 的文件对象，分隔符为制表符
+func NewTSVFile(fileName string, opts CSVOptions) *File {
+	opts.Delimiter = '\t'
+	return NewCSVFile(fileName, opts)
+}
+
+func (w *csvWriter) WriteRow(sheetName string, rowIndex int, values []any) error {
+	sheet, ok := w.index[sheetName]
+	if !ok {
+		file, err := os.CreateTemp("", "excel-csv-*")
+		if err != nil {
+			return fmt.Errorf("create temp file failed: %w", err)
+		}
+		sheet = &csvSheet{name: sheetName, file: file, buf: bufio.NewWriter(file)}
+		w.sheets = append(w.sheets, sheet)
+		w.index[sheetName] = sheet
+	}
+	if rowIndex <= sheet.lastRow {
+		return fmt.Errorf("csv rows must be written in order, row %d after %d", rowIndex, sheet.lastRow)
+	}
+	newline := "\n"
+	if w.opts.CRLF {
+		newline = "\r\n"
+	}
+	for ; sheet.lastRow < rowIndex-1; sheet.lastRow++ {
+		if _, err := sheet.buf.WriteString(newline); err != nil {
+			return err
+		}
+	}
+
+	for i, value := range values {
+		if i > 0 {
+			if _, err := sheet.buf.WriteRune(w.opts.Delimiter); err != nil {
+				return err
+			}
+		}
+		if _, err := sheet.buf.WriteString(w.quote(cellText(value))); err != nil {
+			return err
+		}
+	}
+	if _, err := sheet.buf.WriteString(newline); err != nil {
+		return err
+	}
+	sheet.lastRow = rowIndex
+	return nil
+}
+
+// quote 按引号模式处理字段
+func (w *csvWriter) quote(field string) string {
+	switch w.opts.Quote {
+	case QuoteNone:
+		return strings.Map(func(r rune) rune {
+			if r == w.opts.Delimiter || r == '\r' || r == '\n' {
+				return ' '
+			}
+			return r
+		}, field)
+	case QuoteMinimal:
+		if field == "" || (!strings.ContainsRune(field, w.opts.Delimiter) && !strings.ContainsAny(field, "\"\r\n") &&
+			strings.TrimSpace(field) == field) {
+			return field
+		}
+	}
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
+
+func (w *csvWriter) WriteTo(out io.Writer) (int64, error) {
+	for _, sheet := range w.sheets {
+		if err := sheet.buf.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	counter := &countWriter{w: out}
+	if len(w.sheets) == 1 {
+		return counter.n, w.writeSheet(counter, w.sheets[0])
+	}
+	ext := ".csv"
+	if w.opts.Delimiter == '\t' {
+		ext = ".tsv"
+	}
+	zw := zip.NewWriter(counter)
+	for _, sheet := range w.sheets {
+		entry, err := zw.Create(sheet.name + ext)
+		if err != nil {
+			return counter.n, err
+		}
+		if err := w.writeSheet(entry, sheet); err != nil {
+			return counter.n, err
+		}
+	}
+	return counter.n, zw.Close()
+}
+
+// writeSheet 输出工作表数据，按选项写入 BOM 并转换编码
+func (w *csvWriter) writeSheet(out io.Writer, sheet *csvSheet) error {
+	if _, err := sheet.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if w.opts.Encoding != nil {
+		encoder := transform.NewWriter(out, w.opts.Encoding.NewEncoder())
+		if _, err := io.Copy(encoder, sheet.file); err != nil {
+			return fmt.Errorf("encode sheet %s failed: %w", sheet.name, err)
+		}
+		return encoder.Close()
+	}
+	if w.opts.BOM {
+		if _, err := io.WriteString(out, utf8BOM); err != nil {
+			return err
+		}
+	}
+	_, err := io.Copy(out, sheet.file)
+	return err
+}
+
+func (w *csvWriter) Close() error {
+	var errs []error
+	for _, sheet := range w.sheets {
+		errs = append(errs, sheet.file.Close())
+		if err := os.Remove(sheet.file.Name()); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	w.sheets, w.index = nil, make(map[string]*csvSheet)
+	return errors.Join(errs...)
+}
+
+// countWriter 统计写入的字节数
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// cellText 将单元格值格式化为文本，空值为空字符串
+func cellText(value any) string {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+	switch v := rv.Interface().(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.DateTime)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+// OpenCSVFile 打开 csv/tsv 文件用于导入，数据读取到工作表 CSVSheetName 中，导入函数与 xlsx 一致。
+// 内存占用见 OpenCSVReader
+//
+// 参数:
+//   - path: 文件路径
+//   - opts: csv 选项，分隔符默认为逗号，tsv 需指定制表符
+//
+// 返回:
+//   - *File: 文件对象
+//   - error: 读取失败的错误信息
+func OpenCSVFile(path string, opts CSVOptions) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file failed: %w", err)
+	}
+	defer func(file *os.File) {
+		if err := file.Close(); err != nil {
+			logger.Error("close file error: %w", err)
+		}
+	}(file)
+	return OpenCSVReader(path[strings.LastIndexAny(path, `/\`)+1:], file, opts)
+}
+
+// OpenCSVReader 从 io.Reader 读取 csv/tsv 用于导入，自动跳过 UTF-8 BOM。
+// 数据先转换为只含工作表 CSVSheetName 的 xlsx 再交由 xlsx 的导入函数处理：转换期间内存中保留压缩后的整个工作簿，
+// 解压后超过 excelize.Options.UnzipXMLSizeLimit 的工作表落盘到临时文件，导入时逐行读取
+//
+// 参数:
+//   - fileName: 文件名，用于写出错误工作簿
+//   - reader: 文件内容
+//   - opts: csv 选项，使用其中的分隔符与字符编码
+//
+// 返回:
+//   - *File: 文件对象
+//   - error: 读取失败的错误信息
+func OpenCSVReader(fileName string, reader io.Reader, opts CSVOptions) (*File, error) {
+	if opts.Encoding != nil {
+		reader = transform.NewReader(reader, opts.Encoding.NewDecoder())
+	}
+	br := bufio.NewReader(reader)
+	if prefix, err := br.Peek(len(utf8BOM)); err == nil && string(prefix) == utf8BOM {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+	r := csv.NewReader(br)
+	if opts.Delimiter != 0 {
+		r.Comma = opts.Delimiter
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	file := excelize.NewFile()
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Error("close csv workbook error: %w", err)
+		}
+	}()
+	sw, err := file.NewStreamWriter(CSVSheetName)
+	if err != nil {
+		return nil, err
+	}
+	for rowIndex := 1; ; rowIndex++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv failed: %w", err)
+		}
+		values := make([]any, len(record))
+		for i, field := range record {
+			values[i] = field
+		}
+		cellIndex, _ := excelize.CoordinatesToCellName(1, rowIndex)
+		if err := sw.SetRow(cellIndex, values); err != nil {
+			return nil, err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return nil, err
+	}
+	// 流式写入的数据超过 excelize.StreamChunkSize 后转存到临时文件，无法直接读取，需保存后重新打开
+	buf, err := file.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("write csv workbook failed: %w", err)
+	}
+	return OpenReader(fileName, buf)
+}
@@ -0,0 +1,141 @@
+package excel
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestCSV(t *testing.T) {
+	titles := []string{"id", "姓名", "备注"}
+	keys := []string{"id", "name", "remark"}
+	data := []map[string]any{
+		{"id": 1, "name": "张三", "remark": "a,b"},
+		{"id": 2, "name": "李四", "remark": "say \"hi\""},
+		{"id": 3, "name": "王五", "remark": nil},
+	}
+
+	t.Run("Quote", func(t *testing.T) {
+		f := NewCSVFile("test.csv", CSVOptions{BOM: true})
+		defer f.Close()
+		f.SetCheckFunc(func(key string, value any) error {
+			if key == "remark" && value == nil {
+				return fmt.Errorf("remark is required")
+			}
+			return nil
+		})
+		if err := f.ExportFromDataMap("Sheet1", titles, keys, data); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := f.writer.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		expected := utf8BOM + "id,姓名,备注\n1,张三,\"a,b\"\n2,李四,\"say \"\"hi\"\"\"\n3,王五,\n"
+		if buf.String() != expected {
+			t.Errorf("expected %q, got %q", expected, buf.String())
+		}
+		if errs := f.CheckErrors(); len(errs) != 1 || errs[0].Row != 4 || errs[0].Col != 3 {
+			t.Errorf("unexpected check errors: %v", errs)
+		}
+		if err := f.WriteCell("Sheet1", 1, 1, "id", 1); err != ErrUnsupported {
+			t.Errorf("expected ErrUnsupported, got %v", err)
+		}
+	})
+
+	t.Run("TSVQuoteAll", func(t *testing.T) {
+		f := NewTSVFile("test.tsv", CSVOptions{Quote: QuoteAll, CRLF: true})
+		defer f.Close()
+		if err := f.ExportStreamFromDataMap("Sheet1", titles, keys, data[:1]); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := f.writer.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		expected := "\"id\"\t\"姓名\"\t\"备注\"\r\n\"1\"\t\"张三\"\t\"a,b\"\r\n"
+		if buf.String() != expected {
+			t.Errorf("expected %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("GBKRoundTrip", func(t *testing.T) {
+		opts := CSVOptions{Encoding: simplifiedchinese.GBK}
+		f := NewCSVFile("gbk.csv", opts)
+		defer f.Close()
+		users := []ExportUser{{Id: 1, Name: "张三", Salary: 1.5}, {Id: 2, Name: "李四"}}
+		if err := ExportStreamFromStructs(f, "Sheet1", users); err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		if err := f.WriteToFile(dir); err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := os.ReadFile(filepath.Join(dir, "gbk.csv"))
+		if bytes.Contains(raw, []byte("张三")) {
+			t.Fatal("expected gbk encoded content")
+		}
+
+		in, err := OpenCSVFile(filepath.Join(dir, "gbk.csv"), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer in.Close()
+		result, err := ImportToStructs[ExportUser](in, CSVSheetName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Rows) != 2 || result.Rows[0].Name != "张三" || result.Rows[0].Salary != 1.5 || result.HasError() {
+			t.Errorf("unexpected import result: %+v %v", result.Rows, result.Errors)
+		}
+	})
+
+	t.Run("MultiSheet", func(t *testing.T) {
+		f := NewCSVFile("multi.csv", CSVOptions{})
+		defer f.Close()
+		for _, sheet := range []string{"a", "b"} {
+			if err := f.ExportFromDataMap(sheet, titles, keys, data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var buf bytes.Buffer
+		if _, err := f.writer.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reader.File) != 2 || reader.File[0].Name != "a.csv" || reader.File[1].Name != "b.csv" {
+			t.Errorf("unexpected zip entries")
+		}
+	})
+	t.Run("LargeReader", func(t *testing.T) {
+		// 工作表超过 excelize.StreamChunkSize 时流式写入转存到临时文件，导入仍需读到全部数据
+		var b strings.Builder
+		b.WriteString("id,remark\n")
+		remark := strings.Repeat("x", 8<<10)
+		rows := 3000
+		for i := 1; i <= rows; i++ {
+			_, _ = fmt.Fprintf(&b, "%d,%s\n", i, remark)
+		}
+		f, err := OpenCSVReader("large.csv", strings.NewReader(b.String()), CSVOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		result, err := f.ImportToDataMap(CSVSheetName, []string{"id", "remark"}, []string{"id", "remark"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Rows) != rows || result.Rows[rows-1]["id"] != fmt.Sprint(rows) {
+			t.Errorf("expected %d rows, got %d", rows, len(result.Rows))
+		}
+	})
+}
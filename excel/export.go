@@ -2,7 +2,9 @@ package excel
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -20,6 +22,25 @@ import (
 //   - error: 校验失败的错误信息
 type CheckFunc func(key string, value any) error
 
+// CheckError 导出时校验失败的单元格
+type CheckError struct {
+	Sheet string // 工作表名称
+	Row   int    // 行号（从 1 开始）
+	Col   int    // 列号（从 1 开始）
+	Key   string // 列对应的键
+	Value any    // 单元格的值
+	Err   error
+}
+
+func (e *CheckError) Error() string {
+	cellAddr, _ := excelize.CoordinatesToCellName(e.Col, e.Row)
+	return fmt.Sprintf("sheet %s cell %s: %v", e.Sheet, cellAddr, e.Err)
+}
+
+func (e *CheckError) Unwrap() error {
+	return e.Err
+}
+
 // File excel文件对象
 type File struct {
 	fileName     string
@@ -27,6 +48,19 @@ type File struct {
 	checkFunc    CheckFunc
 	errorStyleId *int
 	titleStyleId *int
+	errorStyle   *excelize.Style // 错误样式定义，用于在错误工作簿中重建样式
+	titleStyle   *excelize.Style
+	cellStyles   map[cellStyle]int       // 单元格样式定义 -> 样式
+	errorStyles  map[int]int             // 列样式 -> 保留数字格式与对齐的错误样式
+	sheetOptions map[string]SheetOptions // 工作表展示选项
+
+	sheetMaxRows int      // 流式导出时每个工作表的最大行数，0 为不滚动
+	splitRows    int      // 流式导出时每个工作簿的最大数据行数，0 为不拆分
+	splitSize    int64    // 流式导出时每个工作簿的最大数据量，0 为不拆分
+	parts        []string // 拆分后已保存的工作簿临时文件
+
+	writer      rowWriter     // 非 xlsx 的写入目标，为空时写入 xlsx 工作簿
+	checkErrors []*CheckError // 导出时校验失败的单元格
 }
 
 func NewFile(fileName string) *File {
@@ -44,24 +78,21 @@ func (f *File) SetCheckFunc(checkFunc CheckFunc) {
 
 func (f *File) SetErrorStyle(style *excelize.Style) error {
 	if style == nil {
-		styleId, err := f.file.NewStyle(&excelize.Style{
+		style = &excelize.Style{
 			Fill: excelize.Fill{
 				Type:    "pattern",
 				Color:   []string{"#FFFF00"}, // 黄色背景
 				Pattern: 1,                   // 实心填充
 			},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create style: %w", err)
-		}
-		f.errorStyleId = &styleId
-	} else {
-		styleId, err := f.file.NewStyle(style)
-		if err != nil {
-			return fmt.Errorf("failed to create style: %w", err)
 		}
-		f.errorStyleId = &styleId
 	}
+	styleId, err := f.file.NewStyle(style)
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+	f.errorStyleId = &styleId
+	f.errorStyle = style
+	f.errorStyles = nil
 	return nil
 }
 
@@ -74,6 +105,7 @@ func (f *File) SetTitleStyle(style *excelize.Style) error {
 		return fmt.Errorf("create title style: %w", err)
 	}
 	f.titleStyleId = &styleId
+	f.titleStyle = style
 	return nil
 }
 
@@ -81,10 +113,30 @@ func (f *File) GetExcelizeFile() *excelize.File {
 	return f.file
 }
 
+// CheckErrors 返回导出时校验失败的单元格。xlsx 中失败的单元格同时标记错误样式与批注，csv 等格式只能通过该方法获取
+func (f *File) CheckErrors() []*CheckError {
+	return f.checkErrors
+}
+
+// check 校验单元格的值，失败时记录并返回错误
+func (f *File) check(sheetName string, rowIndex int, colIndex int, key string, value any) error {
+	if f.checkFunc == nil {
+		return nil
+	}
+	err := f.checkFunc(key, value)
+	if err != nil {
+		f.checkErrors = append(f.checkErrors, &CheckError{Sheet: sheetName, Row: rowIndex, Col: colIndex, Key: key, Value: value, Err: err})
+	}
+	return err
+}
+
 func (f *File) checkParams(sheetName string, titles []string, keys []string) error {
 	if len(titles) != len(keys) {
 		return fmt.Errorf("titles and keys length must be equal")
 	}
+	if f.writer != nil {
+		return nil
+	}
 	sheetIndex, err := f.file.GetSheetIndex(sheetName)
 	if err != nil {
 		return fmt.Errorf("get sheet index: %w", err)
@@ -99,31 +151,60 @@ func (f *File) checkParams(sheetName string, titles []string, keys []string) err
 	return nil
 }
 
-// WriteToFile 将excel数据写入指定位置文件
+// WriteToFile 将excel数据写入指定位置文件，文件名见 OutputName
 //
 // 参数:
 //   - path: 文件路径
 //
 // 返回:
-//   - error: 写入失败的错误信息
+//   - error: 写入或关闭文件失败的错误信息
 func (f *File) WriteToFile(path string) error {
 	if f.fileName == "" {
 		return fmt.Errorf("file name is empty")
 	}
-	file, err := os.Create(filepath.Join(path, f.fileName))
+	file, err := os.Create(filepath.Join(path, f.OutputName()))
 	if err != nil {
 		return err
 	}
-	defer func(file *os.File) {
-		if err := file.Close(); err != nil {
-			logger.Error("close file error: %w", err)
-		}
-	}(file)
+	// 关闭失败时数据可能未完整写入磁盘，需要返回给调用方
+	_, err = f.WriteTo(file)
+	return errors.Join(err, file.Close())
+}
 
-	if err := f.file.Write(file); err != nil {
-		return err
+// WriteTo 将excel数据写入 w，工作簿已拆分时写入包含全部工作簿的 zip，实现 io.WriterTo
+//
+// 参数:
+//   - w: 写入目标
+//
+// 返回:
+//   - int64: 写入的字节数
+//   - error: 写入失败的错误信息
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	if f.writer != nil {
+		return f.writer.WriteTo(w)
 	}
-	return nil
+	if len(f.parts) > 0 {
+		counter := &countWriter{w: w}
+		err := f.writeZip(counter)
+		return counter.n, err
+	}
+	return f.file.WriteTo(w)
+}
+
+// OutputName 输出的文件名：工作簿已拆分或 csv 导出了多个工作表时为 zip 文件名（fileName 替换扩展名为 .zip），否则为 fileName
+func (f *File) OutputName() string {
+	if f.archived() {
+		return f.zipName()
+	}
+	return f.fileName
+}
+
+// archived 返回输出是否为 zip
+func (f *File) archived() bool {
+	if w, ok := f.writer.(*csvWriter); ok {
+		return len(w.sheets) > 1
+	}
+	return len(f.parts) > 0
 }
 
 // ================= 公共逻辑 =================
@@ -138,7 +219,18 @@ func (f *File) WriteToFile(path string) error {
 // 返回:
 //   - error: 写入失败的错误信息
 func (f *File) writeTitles(sheetName string, titles []string, sw *excelize.StreamWriter) error {
-	rowIndex := 1
+	return f.writeTitleRow(sheetName, 1, titles, sw)
+}
+
+// writeTitleRow 在指定行写标题（支持流式/非流式），空标题只设置标题样式
+func (f *File) writeTitleRow(sheetName string, rowIndex int, titles []string, sw *excelize.StreamWriter) error {
+	if f.writer != nil {
+		values := make([]any, len(titles))
+		for i, title := range titles {
+			values[i] = title
+		}
+		return f.writer.WriteRow(sheetName, rowIndex, values)
+	}
 	titleData := make([]any, len(titles))
 
 	for i, title := range titles {
@@ -174,21 +266,36 @@ func (f *File) writeTitles(sheetName string, titles []string, sw *excelize.Strea
 //   - rowIndex: 行索引（从 1 开始）
 //   - keys: 数据键
 //   - row: 数据值
+//   - styles: 各列样式，为空或值为 0 时不设置样式
 //   - sw: 流式写入器
 //
 // 返回:
 //   - error: 写入失败的错误信息
-func (f *File) writeRow(sheetName string, rowIndex int, keys []string, row map[string]any, sw *excelize.StreamWriter) error {
+func (f *File) writeRow(sheetName string, rowIndex int, keys []string, row map[string]any, styles []int, sw *excelize.StreamWriter) error {
 	data := make([]any, len(keys))
+	if f.writer != nil {
+		for j, key := range keys {
+			_ = f.check(sheetName, rowIndex, j+1, key, row[key])
+			data[j] = row[key]
+		}
+		return f.writer.WriteRow(sheetName, rowIndex, data)
+	}
 
 	for j, key := range keys {
 		cellAddr, _ := excelize.CoordinatesToCellName(j+1, rowIndex)
 		cell := excelize.Cell{Value: row[key]}
+		if j < len(styles) {
+			cell.StyleID = styles[j]
+		}
 
-		if f.checkFunc != nil && f.errorStyleId != nil {
-			if err := f.checkFunc(key, row[key]); err != nil {
-				// 添加样式
-				cell.StyleID = *f.errorStyleId
+		if f.errorStyleId != nil {
+			if err := f.check(sheetName, rowIndex, j+1, key, row[key]); err != nil {
+				// 添加样式，保留列的数字格式
+				styleId, err2 := f.errorStyleFor(cell.StyleID)
+				if err2 != nil {
+					return err2
+				}
+				cell.StyleID = styleId
 				// 添加批注
 				if err := f.file.AddComment(sheetName, excelize.Comment{
 					Cell:   cellAddr,
@@ -235,8 +342,11 @@ func (f *File) WriteCell(sheetName string, rowIndex int, colIndex int, key strin
 	if err != nil {
 		return fmt.Errorf("conversion coordinates (%d, %d) to cell name failed: %w", colIndex, rowIndex, err)
 	}
-	if f.checkFunc != nil && f.errorStyleId != nil {
-		if checkErr := f.checkFunc(key, value); checkErr != nil {
+	if f.writer != nil {
+		return ErrUnsupported
+	}
+	if f.errorStyleId != nil {
+		if checkErr := f.check(sheetName, rowIndex, colIndex, key, value); checkErr != nil {
 			if err := f.file.SetCellStyle(sheetName, cellAddr, cellAddr, *f.errorStyleId); err != nil {
 				return fmt.Errorf("failed to set cell style: %w", err)
 			}
@@ -256,7 +366,8 @@ func (f *File) WriteCell(sheetName string, rowIndex int, colIndex int, key strin
 	return nil
 }
 
-func (f *File) iterateRows(sheetName string, keys []string, db *sql.DB, query string, args []any, sw *excelize.StreamWriter) error {
+// iterateRows 逐行读取查询结果，按键转换为map后交给 write 写入
+func (f *File) iterateRows(keys []string, db *sql.DB, query string, args []any, write func(row map[string]any) error) error {
 	rows, err := db.Query(query, args...)
 	if err != nil {
 		return err
@@ -285,9 +396,7 @@ func (f *File) iterateRows(sheetName string, keys []string, db *sql.DB, query st
 		valuePtrs[i] = &values[i]
 	}
 
-	rowIndex := 1
 	for rows.Next() {
-		rowIndex++
 		err = rows.Scan(valuePtrs...)
 		if err != nil {
 			return err
@@ -298,11 +407,11 @@ func (f *File) iterateRows(sheetName string, keys []string, db *sql.DB, query st
 				rowData[key] = values[idx]
 			}
 		}
-		if err := f.writeRow(sheetName, rowIndex, keys, rowData, sw); err != nil {
-			return fmt.Errorf("write row %d err: %w", rowIndex, err)
+		if err := write(rowData); err != nil {
+			return err
 		}
 	}
-	return nil
+	return rows.Err()
 }
 
 // ================= 导出函数 =================
@@ -321,18 +430,24 @@ func (f *File) ExportFromDataMap(sheetName string, titles []string, keys []strin
 	if err := f.checkParams(sheetName, titles, keys); err != nil {
 		return err
 	}
+	l, err := f.newLayout(sheetName, titles, keys, nil, nil)
+	if err != nil {
+		return err
+	}
 
-	err := f.writeTitles(sheetName, titles, nil)
+	err = f.writeHeader(sheetName, l, nil)
 	if err != nil {
 		return err
 	}
-	for i, value := range values {
-		err = f.writeRow(sheetName, i+2, keys, value, nil)
+	rowIndex := l.headerRows()
+	for _, value := range values {
+		l.observe(value)
+		rowIndex, err = f.writeLayoutRow(sheetName, l, rowIndex+1, value, nil)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return f.finishSheet(sheetName, l, rowIndex, nil)
 }
 
 // ExportFromQuery 从数据库查询数据并导出
@@ -351,16 +466,29 @@ func (f *File) ExportFromQuery(sheetName string, titles []string, keys []string,
 	if err := f.checkParams(sheetName, titles, keys); err != nil {
 		return err
 	}
+	l, err := f.newLayout(sheetName, titles, keys, nil, nil)
+	if err != nil {
+		return err
+	}
 
-	err := f.writeTitles(sheetName, titles, nil)
+	err = f.writeHeader(sheetName, l, nil)
 	if err != nil {
 		return err
 	}
 
-	return f.iterateRows(sheetName, keys, db, query, args, nil)
+	rowIndex := l.headerRows()
+	err = f.iterateRows(l.keys, db, query, args, func(row map[string]any) (err error) {
+		l.observe(row)
+		rowIndex, err = f.writeLayoutRow(sheetName, l, rowIndex+1, row, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return f.finishSheet(sheetName, l, rowIndex, nil)
 }
 
-// ExportStreamFromDataMap 从数据源导出数据（流式，适合大规模数据）
+// ExportStreamFromDataMap 从数据源导出数据（流式，适合大规模数据），可通过 SetSheetMaxRows、SetSplitFile 滚动工作表或拆分工作簿
 //
 // 参数:
 //   - sheetName: 工作表名称
@@ -371,33 +499,21 @@ func (f *File) ExportFromQuery(sheetName string, titles []string, keys []string,
 // 返回:
 //   - error: 写入失败的错误信息
 func (f *File) ExportStreamFromDataMap(sheetName string, titles []string, keys []string, values []map[string]any) error {
-	if err := f.checkParams(sheetName, titles, keys); err != nil {
-		return err
-	}
-	sw, err := f.file.NewStreamWriter(sheetName)
-	if err != nil {
-		return err
-	}
-
-	err = f.writeTitles(sheetName, titles, sw)
+	w, err := f.newSheetWriter(sheetName, titles, keys, nil, nil)
 	if err != nil {
 		return err
 	}
-	for i, value := range values {
-		err = f.writeRow(sheetName, i+2, keys, value, sw)
-		if err != nil {
+	for _, value := range values {
+		if err = w.write(value); err != nil {
 			return err
 		}
 	}
 
 	// 结束流式写入
-	if err = sw.Flush(); err != nil {
-		return err
-	}
-	return nil
+	return w.close()
 }
 
-// ExportStreamFromQuery 从数据库查询数据并导出（流式，适合大规模数据）
+// ExportStreamFromQuery 从数据库查询数据并导出（流式，适合大规模数据），可通过 SetSheetMaxRows、SetSplitFile 滚动工作表或拆分工作簿
 //
 // 参数:
 //   - sheetName: 工作表名称
@@ -410,26 +526,14 @@ func (f *File) ExportStreamFromDataMap(sheetName string, titles []string, keys [
 // 返回:
 //   - error: 写入失败的错误信息
 func (f *File) ExportStreamFromQuery(sheetName string, titles []string, keys []string, db *sql.DB, query string, args ...any) error {
-	if err := f.checkParams(sheetName, titles, keys); err != nil {
-		return err
-	}
-	sw, err := f.file.NewStreamWriter(sheetName)
+	w, err := f.newSheetWriter(sheetName, titles, keys, nil, nil)
 	if err != nil {
 		return err
 	}
-
-	if err = f.writeTitles(sheetName, titles, sw); err != nil {
-		return err
-	}
-
-	err = f.iterateRows(sheetName, keys, db, query, args, sw)
-	if err != nil {
+	if err = f.iterateRows(w.layout.keys, db, query, args, w.write); err != nil {
 		return err
 	}
 
 	// 结束流式写入
-	if err = sw.Flush(); err != nil {
-		return err
-	}
-	return nil
+	return w.close()
 }
@@ -0,0 +1,83 @@
+package excel
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Cooooing/cutil/stream"
+)
+
+// ExportFromStream 从流中逐条读取结构体并流式写入，不在内存中保留全部数据。标签规则同 ExportFromStructs，
+// 可通过 SetSheetMaxRows、SetSplitFile 滚动工作表或拆分工作簿。
+// 写入失败或流的上下文取消时停止消费流，已写入的数据保留
+//
+// 参数:
+//   - f: excel文件对象
+//   - sheetName: 工作表名称
+//   - s: 结构体或结构体指针的流
+//
+// 返回:
+//   - int: 写入的数据行数（不含标题行）
+//   - error: 写入失败或上下文取消的错误信息
+func ExportFromStream[T any](f *File, sheetName string, s stream.Stream[T]) (int, error) {
+	return exportRows(f, sheetName, func(write func(T) error) error {
+		return stream.ForEachErr(s, write)
+	})
+}
+
+// ExportFromChannel 从通道中逐条读取结构体并流式写入，直到通道关闭或上下文取消。标签规则同 ExportFromStructs
+//
+// 参数:
+//   - ctx: 上下文，取消时停止写入，已写入的数据保留
+//   - f: excel文件对象
+//   - sheetName: 工作表名称
+//   - ch: 结构体或结构体指针的通道
+//
+// 返回:
+//   - int: 写入的数据行数（不含标题行）
+//   - error: 写入失败或上下文取消的错误信息
+func ExportFromChannel[T any](ctx context.Context, f *File, sheetName string, ch <-chan T) (int, error) {
+	return exportRows(f, sheetName, func(write func(T) error) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case item, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				if err := write(item); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+// exportRows 流式写入结构体数据，consume 逐条调用 write 写入数据。
+// 写入中断时仍结束流式写入，保证已写入的数据有效
+func exportRows[T any](f *File, sheetName string, consume func(write func(T) error) error) (int, error) {
+	columns, err := newStructColumns(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return 0, err
+	}
+	w, err := f.newSheetWriter(sheetName, columns.titles, columns.keys, columns.formats, columns.widths)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = consume(func(item T) error {
+		if err := w.write(columns.row(reflect.ValueOf(&item).Elem())); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+
+	// 结束流式写入
+	if flushErr := w.close(); err == nil {
+		err = flushErr
+	}
+	return count, err
+}
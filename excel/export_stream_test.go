@@ -0,0 +1,69 @@
+package excel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Cooooing/cutil/stream"
+)
+
+func TestExportFromStream(t *testing.T) {
+	f := NewFile("stream.xlsx")
+	users := make([]ExportUser, 100)
+	for i := range users {
+		users[i] = ExportUser{Id: int64(i + 1), Name: "user"}
+	}
+
+	t.Run("NoBlockStream", func(t *testing.T) {
+		count, err := ExportFromStream(f, "NoBlockStream", stream.OfNoBlock(context.Background(), users...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != len(users) {
+			t.Errorf("expected %d rows, got %d", len(users), count)
+		}
+		rows, _ := f.GetExcelizeFile().GetRows("NoBlockStream")
+		if len(rows) != len(users)+1 || rows[100][0] != "100" {
+			t.Errorf("unexpected rows: %d", len(rows))
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var id int64
+		s := stream.GenerateNoBlock(ctx, func() ExportUser {
+			id++
+			if id == 50 {
+				cancel()
+			}
+			return ExportUser{Id: id}
+		})
+		count, err := ExportFromStream(f, "Cancel", s)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		rows, _ := f.GetExcelizeFile().GetRows("Cancel")
+		if count == 0 || len(rows) != count+1 {
+			t.Errorf("expected %d rows written, got %d", count, len(rows)-1)
+		}
+	})
+
+	t.Run("Channel", func(t *testing.T) {
+		ch := make(chan *ExportUser)
+		go func() {
+			defer close(ch)
+			for i := range users {
+				ch <- &users[i]
+			}
+		}()
+		count, err := ExportFromChannel(context.Background(), f, "Channel", ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != len(users) {
+			t.Errorf("expected %d rows, got %d", len(users), count)
+		}
+	})
+}
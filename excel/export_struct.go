@@ -0,0 +1,139 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// defaultTimeFormat 未指定格式的时间字段使用的格式
+const defaultTimeFormat = "yyyy-mm-dd hh:mm:ss"
+
+// structColumns 由结构体字段元信息得到的列定义
+type structColumns struct {
+	metas   []FieldMeta
+	titles  []string
+	keys    []string
+	formats []string
+	widths  []float64
+}
+
+// newStructColumns 解析结构体类型的列定义，未指定格式的时间字段使用默认格式
+func newStructColumns(t reflect.Type) (*structColumns, error) {
+	metas := GetFieldMetas(t)
+	if metas == nil {
+		return nil, fmt.Errorf("export type must be struct, got %v", t)
+	}
+	columns := &structColumns{
+		metas:   metas,
+		titles:  make([]string, len(metas)),
+		keys:    make([]string, len(metas)),
+		formats: make([]string, len(metas)),
+		widths:  make([]float64, len(metas)),
+	}
+	for i, meta := range metas {
+		columns.titles[i] = meta.Title
+		columns.keys[i] = meta.Key
+		columns.formats[i] = meta.Format
+		if columns.formats[i] == "" && isTimeType(meta.Field.Type) {
+			columns.formats[i] = defaultTimeFormat
+		}
+		columns.widths[i] = meta.Width
+	}
+	return columns, nil
+}
+
+// row 将结构体转换为键值map，指针字段解引用，空指针为 nil
+func (c *structColumns) row(value reflect.Value) map[string]any {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	row := make(map[string]any, len(c.metas))
+	for _, meta := range c.metas {
+		field := value.Field(meta.Index)
+		for field.Kind() == reflect.Pointer && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Pointer {
+			row[meta.Key] = nil
+			continue
+		}
+		row[meta.Key] = field.Interface()
+	}
+	return row
+}
+
+func isTimeType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == reflect.TypeOf(time.Time{})
+}
+
+// ExportFromStructs 从结构体集合导出数据，标题、列顺序、列宽与格式由 excel:"title:...;order:...;width:...;format:...;ignore" 标签指定。
+// 校验函数的键为字段名
+//
+// 参数:
+//   - f: excel文件对象
+//   - sheetName: 工作表名称
+//   - values: 结构体或结构体指针集合
+//
+// 返回:
+//   - error: 写入失败的错误信息
+func ExportFromStructs[T any](f *File, sheetName string, values []T) error {
+	columns, err := newStructColumns(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return err
+	}
+	if err := f.checkParams(sheetName, columns.titles, columns.keys); err != nil {
+		return err
+	}
+	l, err := f.newLayout(sheetName, columns.titles, columns.keys, columns.formats, columns.widths)
+	if err != nil {
+		return err
+	}
+	if err := f.writeHeader(sheetName, l, nil); err != nil {
+		return err
+	}
+	rowIndex := l.headerRows()
+	for i := range values {
+		row := columns.row(reflect.ValueOf(&values[i]).Elem())
+		l.observe(row)
+		if rowIndex, err = f.writeLayoutRow(sheetName, l, rowIndex+1, row, nil); err != nil {
+			return err
+		}
+	}
+	return f.finishSheet(sheetName, l, rowIndex, nil)
+}
+
+// ExportStreamFromStructs 从结构体集合导出数据（流式，适合大规模数据），标签规则同 ExportFromStructs，
+// 可通过 SetSheetMaxRows、SetSplitFile 滚动工作表或拆分工作簿
+//
+// 参数:
+//   - f: excel文件对象
+//   - sheetName: 工作表名称
+//   - values: 结构体或结构体指针集合
+//
+// 返回:
+//   - error: 写入失败的错误信息
+func ExportStreamFromStructs[T any](f *File, sheetName string, values []T) error {
+	columns, err := newStructColumns(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return err
+	}
+	w, err := f.newSheetWriter(sheetName, columns.titles, columns.keys, columns.formats, columns.widths)
+	if err != nil {
+		return err
+	}
+	for i := range values {
+		if err := w.write(columns.row(reflect.ValueOf(&values[i]).Elem())); err != nil {
+			return err
+		}
+	}
+
+	// 结束流式写入
+	return w.close()
+}
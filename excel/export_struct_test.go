@@ -0,0 +1,69 @@
+package excel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type ExportUser struct {
+	Id       int64     `excel:"title:编号;order:1"`
+	Name     string    `excel:"title:姓名;order:2;width:20"`
+	Salary   float64   `excel:"title:薪资;order:4;format:#,##0.00"`
+	Birthday time.Time `excel:"title:生日;order:3;format:yyyy-mm-dd"`
+	Age      *int      `excel:"title:年龄"`
+	Password string    `excel:"ignore"`
+}
+
+func TestExportFromStructs(t *testing.T) {
+	age := 30
+	users := []*ExportUser{
+		{Id: 1, Name: "张三", Salary: 12345.678, Birthday: time.Date(2000, 1, 2, 0, 0, 0, 0, time.Local), Age: &age},
+		{Id: 2, Name: "李四", Salary: -1, Birthday: time.Date(2001, 3, 4, 0, 0, 0, 0, time.Local)},
+	}
+	f := NewFile("struct.xlsx")
+	f.SetCheckFunc(func(key string, value any) error {
+		if key == "Salary" && value.(float64) < 0 {
+			return fmt.Errorf("salary must not be negative")
+		}
+		return nil
+	})
+
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			sheet := fmt.Sprintf("stream_%v", stream)
+			var err error
+			if stream {
+				err = ExportStreamFromStructs(f, sheet, users)
+			} else {
+				err = ExportFromStructs(f, sheet, users)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			ef := f.GetExcelizeFile()
+			rows, err := ef.GetRows(sheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := [][]string{
+				{"编号", "姓名", "生日", "薪资", "年龄"},
+				{"1", "张三", "2000-01-02", "12,345.68", "30"},
+				{"2", "李四", "2001-03-04", "-1.00"},
+			}
+			if fmt.Sprint(rows) != fmt.Sprint(expected) {
+				t.Errorf("expected %v, got %v", expected, rows)
+			}
+			if width, _ := ef.GetColWidth(sheet, "B"); width != 20 {
+				t.Errorf("expected column B width 20, got %v", width)
+			}
+			// 校验失败的单元格保留数字格式
+			if value, _ := ef.GetCellValue(sheet, "D3"); value != "-1.00" {
+				t.Errorf("expected formatted error cell, got %q", value)
+			}
+			if style, _ := ef.GetCellStyle(sheet, "D3"); style == *f.errorStyleId || style == 0 {
+				t.Errorf("expected error style with number format on D3, got %d", style)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Header 多级表头节点，叶子节点对应一列数据
+type Header struct {
+	Title    string   // 标题
+	Key      string   // 叶子节点对应的数据键
+	Children []Header // 子表头，为空时为叶子节点
+}
+
+// MergeSameValues 合并数据列中连续相同的值，在导出该工作表之前设置。
+// 多个列按顺序分级，前面的列值变化时后面的列同时断开合并，空值不合并
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - keys: 需要合并的列对应的键
+func (f *File) MergeSameValues(sheetName string, keys ...string) {
+	opts := f.sheetOptions[sheetName]
+	opts.MergeKeys = append(opts.MergeKeys, keys...)
+	f.SetSheetOptions(sheetName, opts)
+}
+
+// buildHeader 将表头树展开为表头行与合并区域
+//
+// 参数:
+//   - headers: 表头树
+//
+// 返回:
+//   - [][]string: 表头各行，合并区域中除左上角外的单元格为空
+//   - []string: 合并区域，如 A1:C1
+//   - []string: 叶子节点标题
+//   - []string: 叶子节点数据键
+//   - error: 表头定义错误
+func buildHeader(headers []Header) ([][]string, []string, []string, []string, error) {
+	depth := headerDepth(headers)
+	var (
+		titles, keys []string
+		cells        []headerCell
+	)
+	var walk func(nodes []Header, level int) error
+	walk = func(nodes []Header, level int) error {
+		for _, node := range nodes {
+			start := len(keys) + 1
+			if len(node.Children) == 0 {
+				if node.Key == "" {
+					return fmt.Errorf("header %s must have key", node.Title)
+				}
+				titles = append(titles, node.Title)
+				keys = append(keys, node.Key)
+				// 叶子节点纵向合并到最后一行表头
+				cells = append(cells, headerCell{title: node.Title, row: level + 1, col: start, endRow: depth, endCol: start})
+				continue
+			}
+			if err := walk(node.Children, level+1); err != nil {
+				return err
+			}
+			cells = append(cells, headerCell{title: node.Title, row: level + 1, col: start, endRow: level + 1, endCol: len(keys)})
+		}
+		return nil
+	}
+	if err := walk(headers, 0); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	rows := make([][]string, depth)
+	for i := range rows {
+		rows[i] = make([]string, len(keys))
+	}
+	var merges []string
+	for _, cell := range cells {
+		rows[cell.row-1][cell.col-1] = cell.title
+		if cell.endRow > cell.row || cell.endCol > cell.col {
+			topLeft, _ := excelize.CoordinatesToCellName(cell.col, cell.row)
+			bottomRight, _ := excelize.CoordinatesToCellName(cell.endCol, cell.endRow)
+			merges = append(merges, topLeft+":"+bottomRight)
+		}
+	}
+	return rows, merges, titles, keys, nil
+}
+
+type headerCell struct {
+	title          string
+	row, col       int
+	endRow, endCol int
+}
+
+func headerDepth(headers []Header) int {
+	depth := 0
+	for _, header := range headers {
+		depth = max(depth, headerDepth(header.Children)+1)
+	}
+	return depth
+}
+
+// writeHeader 写入表头行并合并表头单元格（支持流式/非流式）
+func (f *File) writeHeader(sheetName string, l *layout, sw *excelize.StreamWriter) error {
+	for i, titles := range l.header {
+		if err := f.writeTitleRow(sheetName, i+1, titles, sw); err != nil {
+			return err
+		}
+	}
+	return f.mergeCells(sheetName, l.headerMerges, sw)
+}
+
+// mergeCells 合并单元格（支持流式/非流式），csv 等格式不合并
+func (f *File) mergeCells(sheetName string, ranges []string, sw *excelize.StreamWriter) error {
+	if f.writer != nil {
+		return nil
+	}
+	for _, ref := range ranges {
+		topLeft, bottomRight, _ := strings.Cut(ref, ":")
+		var err error
+		if sw != nil {
+			err = sw.MergeCell(topLeft, bottomRight)
+		} else {
+			err = f.file.MergeCell(sheetName, topLeft, bottomRight)
+		}
+		if err != nil {
+			return fmt.Errorf("merge cell %s failed: %w", ref, err)
+		}
+	}
+	return nil
+}
+
+// trackMerges 记录数据行中需要合并的列的值，返回值变化时已结束的合并区域
+func (l *layout) trackMerges(rowIndex int, row map[string]any) []string {
+	var ranges []string
+	broken := false
+	for j, index := range l.mergeIndex {
+		value := cellText(row[l.keys[index]])
+		if broken || l.runStart[j] == 0 || value != l.runValue[j] || value == "" {
+			ranges = l.closeRun(ranges, j, rowIndex-1)
+			l.runStart[j], l.runValue[j] = rowIndex, value
+			broken = true
+		}
+	}
+	return ranges
+}
+
+// flushMerges 结束全部合并区域，返回需要合并的区域
+func (l *layout) flushMerges(lastRow int) []string {
+	var ranges []string
+	for j := range l.mergeIndex {
+		ranges = l.closeRun(ranges, j, lastRow)
+		l.runStart[j], l.runValue[j] = 0, ""
+	}
+	return ranges
+}
+
+func (l *layout) closeRun(ranges []string, j int, endRow int) []string {
+	start := l.runStart[j]
+	if start == 0 || endRow <= start || l.runValue[j] == "" {
+		return ranges
+	}
+	col, _ := excelize.ColumnNumberToName(l.mergeIndex[j] + 1)
+	return append(ranges, fmt.Sprintf("%s%d:%s%d", col, start, col, endRow))
+}
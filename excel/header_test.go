@@ -0,0 +1,167 @@
+package excel
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestHeaderAndMerge(t *testing.T) {
+	headers := []Header{
+		{Title: "地区", Key: "region"},
+		{Title: "城市", Key: "city"},
+		{Title: "第一季度", Children: []Header{
+			{Title: "一月", Key: "jan"},
+			{Title: "二月", Key: "feb"},
+			{Title: "三月", Key: "mar"},
+		}},
+	}
+	data := []map[string]any{
+		{"region": "华东", "city": "上海", "jan": 1, "feb": 2, "mar": 3},
+		{"region": "华东", "city": "上海", "jan": 4, "feb": 5, "mar": 6},
+		{"region": "华东", "city": "杭州", "jan": 7, "feb": 8, "mar": 9},
+		{"region": "华北", "city": "杭州", "jan": 1, "feb": 1, "mar": 1},
+		{"region": "华北", "city": "北京", "jan": 2, "feb": 2, "mar": 2},
+	}
+
+	src := NewFile("header.xlsx")
+	for _, stream := range []bool{false, true} {
+		sheet := fmt.Sprintf("stream_%v", stream)
+		src.SetSheetOptions(sheet, SheetOptions{Headers: headers, FreezeRows: 2})
+		src.MergeSameValues(sheet, "region", "city")
+		var err error
+		if stream {
+			err = src.ExportStreamFromDataMap(sheet, nil, nil, data)
+		} else {
+			err = src.ExportFromDataMap(sheet, nil, nil, data)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := src.GetExcelizeFile().WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// 城市在地区变化时断开，第 4、5 行的杭州不与第 3 行合并
+	expected := []string{"A1:A2", "B1:B2", "C1:E1", "A3:A5", "B3:B4", "A6:A7"}
+	for _, stream := range []bool{false, true} {
+		sheet := fmt.Sprintf("stream_%v", stream)
+		t.Run(sheet, func(t *testing.T) {
+			merges, err := f.GetMergeCells(sheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var refs []string
+			for _, merge := range merges {
+				refs = append(refs, merge.GetStartAxis()+":"+merge.GetEndAxis())
+			}
+			slices.Sort(refs)
+			want := slices.Sorted(slices.Values(expected))
+			if !slices.Equal(refs, want) {
+				t.Errorf("expected merges %v, got %v", want, refs)
+			}
+
+			if value, _ := f.GetCellValue(sheet, "C1"); value != "第一季度" {
+				t.Errorf("expected group title, got %q", value)
+			}
+			if value, _ := f.GetCellValue(sheet, "E2"); value != "三月" {
+				t.Errorf("expected leaf title, got %q", value)
+			}
+			if value, _ := f.GetCellValue(sheet, "E7"); value != "2" {
+				t.Errorf("expected data after header rows, got %q", value)
+			}
+		})
+	}
+}
+
+func TestBuildHeaderMissingKey(t *testing.T) {
+	_, _, _, _, err := buildHeader([]Header{{Title: "分组", Children: []Header{{Title: "无键"}}}})
+	if err == nil {
+		t.Fatal("expected error for leaf header without key")
+	}
+}
+
+// staticConnector 任意查询均返回固定的列与数据行
+type staticConnector struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (c *staticConnector) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *staticConnector) Driver() driver.Driver                        { return nil }
+func (c *staticConnector) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (c *staticConnector) Close() error                                 { return nil }
+func (c *staticConnector) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+
+func (c *staticConnector) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &staticRows{columns: c.columns, rows: c.rows}, nil
+}
+
+type staticRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *staticRows) Columns() []string { return r.columns }
+func (r *staticRows) Close() error      { return nil }
+
+func (r *staticRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestHeaderFromQuery(t *testing.T) {
+	headers := []Header{
+		{Title: "城市", Key: "city"},
+		{Title: "第一季度", Children: []Header{
+			{Title: "一月", Key: "jan"},
+			{Title: "二月", Key: "feb"},
+		}},
+	}
+	db := sql.OpenDB(&staticConnector{
+		columns: []string{"city", "jan", "feb"},
+		rows:    [][]driver.Value{{"上海", int64(1), int64(2)}, {"杭州", int64(3), int64(4)}},
+	})
+	defer db.Close()
+
+	f := NewFile("header.xlsx")
+	for _, stream := range []bool{false, true} {
+		sheet := fmt.Sprintf("stream_%v", stream)
+		f.SetSheetOptions(sheet, SheetOptions{Headers: headers})
+		var err error
+		if stream {
+			err = f.ExportStreamFromQuery(sheet, nil, nil, db, "SELECT city, jan, feb FROM sales")
+		} else {
+			err = f.ExportFromQuery(sheet, nil, nil, db, "SELECT city, jan, feb FROM sales")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(sheet, func(t *testing.T) {
+			rows, err := f.GetExcelizeFile().GetRows(sheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 4 || !slices.Equal(rows[3], []string{"杭州", "3", "4"}) {
+				t.Errorf("unexpected rows %v", rows)
+			}
+		})
+	}
+}
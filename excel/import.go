@@ -0,0 +1,498 @@
+package excel
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cooooing/cutil/base/logger"
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrTitleNotFound 标题行中缺少需要导入的列
+var ErrTitleNotFound = errors.New("title not found")
+
+// timeLayouts 字符串单元格解析时间时尝试的格式
+var timeLayouts = []string{
+	time.RFC3339,
+	time.DateTime,
+	time.DateOnly,
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"2006/1/2 15:04:05",
+	"2006/1/2",
+}
+
+// ImportError 导入时单元格转换或校验失败的错误
+type ImportError struct {
+	Row   int    // 行号（从 1 开始，标题行为第 1 行）
+	Col   int    // 列号（从 1 开始）
+	Title string // 列标题
+	Key   string // 列对应的键
+	Value string // 单元格原始值
+	Err   error
+}
+
+func (e *ImportError) Error() string {
+	cellAddr, _ := excelize.CoordinatesToCellName(e.Col, e.Row)
+	return fmt.Sprintf("cell %s (%s): %v", cellAddr, e.Title, e.Err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult 导入结果
+type ImportResult[T any] struct {
+	Rows      []T            // 转换与校验均通过的行
+	Errors    []*ImportError // 失败单元格的错误，按行列顺序
+	ErrorFile *File          // 错误工作簿，包含标题行与出错的行，出错单元格标记错误样式与批注；没有错误时为 nil
+}
+
+// HasError 返回是否存在导入错误
+func (r *ImportResult[T]) HasError() bool {
+	return len(r.Errors) > 0
+}
+
+// importColumn 导入列
+type importColumn struct {
+	title string
+	key   string
+	index int // 在工作表中的列索引（从 0 开始）
+	typ   reflect.Type
+}
+
+// OpenFile 打开 excel 文件用于导入
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - *File: excel文件对象
+//   - error: 打开失败的错误信息
+func OpenFile(path string) (*File, error) {
+	file, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file failed: %w", err)
+	}
+	return newOpenedFile(filepath.Base(path), file), nil
+}
+
+// OpenReader 从 io.Reader 读取 excel 文件用于导入，如上传的文件
+//
+// 参数:
+//   - fileName: 文件名，用于写出错误工作簿
+//   - reader: 文件内容
+//
+// 返回:
+//   - *File: excel文件对象
+//   - error: 读取失败的错误信息
+func OpenReader(fileName string, reader io.Reader) (*File, error) {
+	file, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("open reader failed: %w", err)
+	}
+	return newOpenedFile(fileName, file), nil
+}
+
+func newOpenedFile(fileName string, file *excelize.File) *File {
+	f := &File{fileName: fileName, file: file}
+	_ = f.SetErrorStyle(nil)
+	return f
+}
+
+// ImportToDataMap 读取工作表数据为键值map，按标题行匹配列，单元格值为字符串，空单元格为 nil
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - titles: 标题行
+//   - keys: 标题对应的键
+//
+// 返回:
+//   - *ImportResult[map[string]any]: 导入结果
+//   - error: 读取失败或缺少标题的错误信息
+func (f *File) ImportToDataMap(sheetName string, titles []string, keys []string) (*ImportResult[map[string]any], error) {
+	if len(titles) != len(keys) {
+		return nil, fmt.Errorf("titles and keys length must be equal")
+	}
+	columns := make([]*importColumn, len(titles))
+	for i := range titles {
+		columns[i] = &importColumn{title: titles[i], key: keys[i]}
+	}
+	result := &ImportResult[map[string]any]{}
+	errorFile, errs, err := f.importSheet(sheetName, columns, func(values []any) {
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			row[column.key] = values[i]
+		}
+		result.Rows = append(result.Rows, row)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Errors, result.ErrorFile = errs, errorFile
+	return result, nil
+}
+
+// ImportToStructs 读取工作表数据为结构体，按 excel:"title:..." 标签匹配标题行，单元格值转换为字段类型。
+// 支持字符串、整数、浮点数、布尔、time.Time、encoding.TextUnmarshaler 及其指针，空单元格保留零值
+//
+// 参数:
+//   - f: excel文件对象
+//   - sheetName: 工作表名称
+//
+// 返回:
+//   - *ImportResult[*T]: 导入结果
+//   - error: 读取失败或缺少标题的错误信息
+func ImportToStructs[T any](f *File, sheetName string) (*ImportResult[*T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("import type must be struct, got %v", t)
+	}
+	metas := getFieldMetas(t)
+	columns := make([]*importColumn, len(metas))
+	for i, meta := range metas {
+		columns[i] = &importColumn{title: meta.Title, key: meta.Key, typ: meta.Field.Type}
+	}
+	result := &ImportResult[*T]{}
+	errorFile, errs, err := f.importSheet(sheetName, columns, func(values []any) {
+		item := new(T)
+		v := reflect.ValueOf(item).Elem()
+		for i, meta := range metas {
+			if values[i] != nil {
+				v.Field(meta.Index).Set(reflect.ValueOf(values[i]))
+			}
+		}
+		result.Rows = append(result.Rows, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Errors, result.ErrorFile = errs, errorFile
+	return result, nil
+}
+
+// importSheet 逐行读取工作表，转换并校验单元格，全部通过的行交给 emit 处理
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - columns: 导入列，typ 为空或字符串时读取单元格格式化后的值（typ 为空时保留为字符串），其他类型读取原始值
+//   - emit: 处理通过的行，values 与 columns 一一对应，空单元格为 nil
+//
+// 返回:
+//   - *File: 错误工作簿，没有错误时为 nil
+//   - []*ImportError: 单元格错误
+//   - error: 读取失败或缺少标题的错误信息
+func (f *File) importSheet(sheetName string, columns []*importColumn, emit func(values []any)) (*File, []*ImportError, error) {
+	rows, err := f.file.Rows(sheetName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read sheet %s failed: %w", sheetName, err)
+	}
+	defer func(rows *excelize.Rows) {
+		if err := rows.Close(); err != nil {
+			logger.Error("rows.Close() error: %w", err)
+		}
+	}(rows)
+
+	// 字符串目标读取单元格格式化后的值（如日期单元格显示的日期，而非序列值），与原始值同步逐行读取
+	formatted, err := f.file.Rows(sheetName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read sheet %s failed: %w", sheetName, err)
+	}
+	defer func(rows *excelize.Rows) {
+		if err := rows.Close(); err != nil {
+			logger.Error("rows.Close() error: %w", err)
+		}
+	}(formatted)
+
+	if !rows.Next() || !formatted.Next() {
+		return nil, nil, fmt.Errorf("sheet %s has no title row", sheetName)
+	}
+	header, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read title row failed: %w", err)
+	}
+	titleIndex := make(map[string]int, len(header))
+	for i, title := range header {
+		// 模板中必填列的标题带有 RequiredMark 前缀
+		title = strings.TrimPrefix(strings.TrimSpace(title), RequiredMark)
+		if _, exists := titleIndex[title]; !exists && title != "" {
+			titleIndex[title] = i
+		}
+	}
+	for _, column := range columns {
+		index, exists := titleIndex[column.title]
+		if !exists {
+			return nil, nil, fmt.Errorf("%w: %s", ErrTitleNotFound, column.title)
+		}
+		column.index = index
+	}
+
+	var (
+		errs      []*ImportError
+		errorFile *File
+		errorRow  = 1
+	)
+	rowIndex := 1
+	for rows.Next() {
+		rowIndex++
+		cells, err := rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, nil, fmt.Errorf("read row %d failed: %w", rowIndex, err)
+		}
+		formatted.Next()
+		displays, err := formatted.Columns()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read row %d failed: %w", rowIndex, err)
+		}
+		if isBlankRow(cells) {
+			continue
+		}
+
+		values := make([]any, len(columns))
+		var rowErrs []*ImportError
+		for i, column := range columns {
+			var raw string
+			if isStringType(column.typ) {
+				if column.index < len(displays) {
+					raw = displays[column.index]
+				}
+			} else if column.index < len(cells) {
+				raw = cells[column.index]
+			}
+			value, err := convertCell(strings.TrimSpace(raw), column.typ)
+			if err == nil && f.checkFunc != nil {
+				err = f.checkFunc(column.key, value)
+			}
+			if err != nil {
+				rowErrs = append(rowErrs, &ImportError{
+					Row: rowIndex, Col: column.index + 1, Title: column.title, Key: column.key, Value: raw, Err: err,
+				})
+				continue
+			}
+			values[i] = value
+		}
+		if len(rowErrs) == 0 {
+			emit(values)
+			continue
+		}
+
+		errs = append(errs, rowErrs...)
+		if errorFile == nil {
+			if errorFile, err = f.newErrorFile(sheetName, header); err != nil {
+				return nil, nil, err
+			}
+		}
+		errorRow++
+		if err := errorFile.writeErrorRow(sheetName, errorRow, cells, displays, rowErrs); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := rows.Error(); err != nil {
+		return nil, nil, fmt.Errorf("read sheet %s failed: %w", sheetName, err)
+	}
+	return errorFile, errs, nil
+}
+
+// newErrorFile 创建错误工作簿并写入标题行，沿用当前文件的错误样式与标题样式
+func (f *File) newErrorFile(sheetName string, header []string) (*File, error) {
+	errorFile := NewFile(f.fileName)
+	if f.errorStyle != nil {
+		if err := errorFile.SetErrorStyle(f.errorStyle); err != nil {
+			return nil, err
+		}
+	}
+	if f.titleStyle != nil {
+		if err := errorFile.SetTitleStyle(f.titleStyle); err != nil {
+			return nil, err
+		}
+	}
+	if err := errorFile.file.SetSheetName(errorFile.file.GetSheetName(0), sheetName); err != nil {
+		return nil, fmt.Errorf("rename sheet failed: %w", err)
+	}
+	if err := errorFile.writeTitles(sheetName, header, nil); err != nil {
+		return nil, err
+	}
+	return errorFile, nil
+}
+
+// writeErrorRow 将出错行写入错误工作簿，出错单元格标记错误样式并添加批注
+// 单元格写入格式化后的显示值（日期等保持原样显示），未格式化的数值仍以数值写入
+func (f *File) writeErrorRow(sheetName string, rowIndex int, cells, displays []string, rowErrs []*ImportError) error {
+	for i := 0; i < max(len(cells), len(displays)); i++ {
+		var raw, display string
+		if i < len(cells) {
+			raw = cells[i]
+		}
+		if i < len(displays) {
+			display = displays[i]
+		}
+		var value any = display
+		if display == raw {
+			// 仅规范写法的数值转回数值，避免 "00123" 这类文本丢失前导零
+			if number, err := strconv.ParseFloat(raw, 64); err == nil && strconv.FormatFloat(number, 'f', -1, 64) == raw {
+				value = number
+			}
+		}
+		cellAddr, _ := excelize.CoordinatesToCellName(i+1, rowIndex)
+		if err := f.file.SetCellValue(sheetName, cellAddr, value); err != nil {
+			return fmt.Errorf("failed to set cell value: %w", err)
+		}
+	}
+	for _, rowErr := range rowErrs {
+		cellAddr, _ := excelize.CoordinatesToCellName(rowErr.Col, rowIndex)
+		if err := f.file.SetCellStyle(sheetName, cellAddr, cellAddr, *f.errorStyleId); err != nil {
+			return fmt.Errorf("failed to set cell style: %w", err)
+		}
+		if err := f.file.AddComment(sheetName, excelize.Comment{
+			Cell:   cellAddr,
+			Author: "System",
+			Text:   rowErr.Err.Error(),
+		}); err != nil {
+			return fmt.Errorf("failed to add comment: %w", err)
+		}
+	}
+	return nil
+}
+
+func isBlankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isStringType 判断导入目标是否为字符串，typ 为空时单元格值保留为字符串
+func isStringType(typ reflect.Type) bool {
+	if typ == nil {
+		return true
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.String
+}
+
+// convertCell 将单元格原始值转换为指定类型，typ 为空时返回字符串，空值返回 nil
+func convertCell(raw string, typ reflect.Type) (any, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if typ == nil {
+		return raw, nil
+	}
+	v, err := convertValue(raw, typ)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func convertValue(raw string, typ reflect.Type) (reflect.Value, error) {
+	if typ.Kind() == reflect.Pointer {
+		elem, err := convertValue(raw, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	v := reflect.New(typ).Elem()
+	if typ == reflect.TypeOf(time.Time{}) {
+		t, err := parseTime(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Set(reflect.ValueOf(t))
+		return v, nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(raw)); err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid value %q: %w", raw, err)
+		}
+		return v, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := parseBool(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, typ.Bits())
+		if err != nil {
+			// 数值单元格可能以浮点形式存储，如 1.0、1E+3
+			fv, ferr := strconv.ParseFloat(raw, 64)
+			if ferr != nil || fv != float64(int64(fv)) || v.OverflowInt(int64(fv)) {
+				return reflect.Value{}, fmt.Errorf("invalid integer %q", raw)
+			}
+			n = int64(fv)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, typ.Bits())
+		if err != nil {
+			fv, ferr := strconv.ParseFloat(raw, 64)
+			if ferr != nil || fv < 0 || fv != float64(uint64(fv)) || v.OverflowUint(uint64(fv)) {
+				return reflect.Value{}, fmt.Errorf("invalid unsigned integer %q", raw)
+			}
+			n = uint64(fv)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		fv, err := strconv.ParseFloat(raw, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(fv)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported field type %v", typ)
+	}
+	return v, nil
+}
+
+// parseTime 解析时间，支持 excel 日期序列值与常见日期格式
+func parseTime(raw string) (time.Time, error) {
+	if serial, err := strconv.ParseFloat(raw, 64); err == nil {
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q: %w", raw, err)
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
+
+// parseBool 解析布尔值，额外支持 是/否
+func parseBool(raw string) (bool, error) {
+	switch raw {
+	case "是", "Y", "y", "yes", "YES":
+		return true, nil
+	case "否", "N", "n", "no", "NO":
+		return false, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean %q", raw)
+	}
+	return b, nil
+}
@@ -0,0 +1,206 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type ImportUser struct {
+	Id       int64      `excel:"title:id"`
+	Name     string     `excel:"title:姓名"`
+	Age      *int       `excel:"title:年龄"`
+	Birthday time.Time  `excel:"title:生日"`
+	Vip      bool       `excel:"title:会员"`
+	Login    *time.Time `excel:"-"`
+	remark   string
+}
+
+func TestImport(t *testing.T) {
+	src := NewFile("import.xlsx")
+	titles := []string{"id", "姓名", "年龄", "生日", "会员"}
+	keys := []string{"id", "name", "age", "birthday", "vip"}
+	err := src.ExportFromDataMap("Sheet1", titles, keys, []map[string]any{
+		{"id": 1, "name": "张三", "age": 18, "birthday": time.Date(2000, 1, 2, 0, 0, 0, 0, time.Local), "vip": "是"},
+		{"id": 2, "name": "李四", "age": "十九", "birthday": "2001-03-04", "vip": false},
+		{"id": 3, "name": "王五", "age": 30, "birthday": "2002/5/6", "vip": true},
+		{"id": 4, "name": "", "birthday": "2003-07-08", "vip": "否"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := src.GetExcelizeFile().WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenReader("import.xlsx", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.SetCheckFunc(func(key string, value any) error {
+		if key == "Name" && value == nil {
+			return fmt.Errorf("name is required")
+		}
+		if key == "Age" && value != nil && *value.(*int) > 22 {
+			return fmt.Errorf("age is greater than 22")
+		}
+		return nil
+	})
+
+	t.Run("ImportToStructs", func(t *testing.T) {
+		result, err := ImportToStructs[ImportUser](f, "Sheet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Rows) != 1 || result.Rows[0].Name != "张三" || *result.Rows[0].Age != 18 || !result.Rows[0].Vip {
+			t.Fatalf("unexpected rows: %+v", result.Rows)
+		}
+		if !result.Rows[0].Birthday.Equal(time.Date(2000, 1, 2, 0, 0, 0, 0, time.Local)) {
+			t.Errorf("unexpected birthday: %v", result.Rows[0].Birthday)
+		}
+
+		expected := []string{"C3", "C4", "B5"}
+		if len(result.Errors) != len(expected) {
+			t.Fatalf("expected %d errors, got %v", len(expected), result.Errors)
+		}
+		for i, e := range result.Errors {
+			if cell := fmt.Sprintf("%c%d", 'A'+e.Col-1, e.Row); cell != expected[i] {
+				t.Errorf("error %d: expected cell %s, got %s", i, expected[i], cell)
+			}
+		}
+
+		if result.ErrorFile == nil {
+			t.Fatal("expected error file")
+		}
+		ef := result.ErrorFile.GetExcelizeFile()
+		if value, _ := ef.GetCellValue("Sheet1", "B3"); value != "王五" {
+			t.Errorf("expected error row 王五 in row 3, got %q", value)
+		}
+		comments, err := ef.GetComments("Sheet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(comments) != 3 {
+			t.Errorf("expected 3 comments, got %d", len(comments))
+		}
+		if style, _ := ef.GetCellStyle("Sheet1", "C2"); style != *result.ErrorFile.errorStyleId {
+			t.Errorf("expected error style on C2")
+		}
+	})
+
+	t.Run("ImportToDataMap", func(t *testing.T) {
+		result, err := f.ImportToDataMap("Sheet1", []string{"姓名", "年龄"}, []string{"Name", "age"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Rows) != 3 || result.Rows[1]["age"] != "十九" {
+			t.Errorf("unexpected rows: %v", result.Rows)
+		}
+		if len(result.Errors) != 1 || result.Errors[0].Row != 5 {
+			t.Errorf("unexpected errors: %v", result.Errors)
+		}
+	})
+
+	t.Run("TitleNotFound", func(t *testing.T) {
+		_, err := f.ImportToDataMap("Sheet1", []string{"邮箱"}, []string{"email"})
+		if !errors.Is(err, ErrTitleNotFound) {
+			t.Errorf("expected ErrTitleNotFound, got %v", err)
+		}
+	})
+}
+
+func TestImportDateCellToString(t *testing.T) {
+	src := excelize.NewFile()
+	style, err := src.NewStyle(&excelize.Style{NumFmt: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = src.SetSheetRow("Sheet1", "A1", &[]any{"入职日期", "日期"})
+	_ = src.SetSheetRow("Sheet1", "A2", &[]any{45292, 45292})
+	_ = src.SetCellStyle("Sheet1", "A2", "B2", style)
+	want, _ := src.GetCellValue("Sheet1", "A2")
+	buf, err := src.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenReader("date.xlsx", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	type Row struct {
+		Joined string    `excel:"title:入职日期"`
+		Date   time.Time `excel:"title:日期"`
+	}
+	result, err := ImportToStructs[Row](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0].Joined != want || want == "45292" {
+		t.Fatalf("expected formatted date %q, got %+v", want, result.Rows)
+	}
+	if !result.Rows[0].Date.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("unexpected date %v", result.Rows[0].Date)
+	}
+	mapResult, err := f.ImportToDataMap("Sheet1", []string{"入职日期"}, []string{"joined"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapResult.Rows[0]["joined"] != want {
+		t.Errorf("expected formatted date %q in map, got %v", want, mapResult.Rows[0]["joined"])
+	}
+}
+
+func TestImportErrorFileKeepsCellValues(t *testing.T) {
+	src := excelize.NewFile()
+	style, err := src.NewStyle(&excelize.Style{NumFmt: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = src.SetSheetRow("Sheet1", "A1", &[]any{"日期", "金额", "编号", "年龄"})
+	_ = src.SetSheetRow("Sheet1", "A2", &[]any{45292, 12.5, "00123", "abc"})
+	_ = src.SetCellStyle("Sheet1", "A2", "A2", style)
+	wantDate, _ := src.GetCellValue("Sheet1", "A2")
+	buf, err := src.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenReader("error.xlsx", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	type Row struct {
+		Date   time.Time `excel:"title:日期"`
+		Amount float64   `excel:"title:金额"`
+		Code   string    `excel:"title:编号"`
+		Age    int       `excel:"title:年龄"`
+	}
+	result, err := ImportToStructs[Row](f, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ErrorFile == nil {
+		t.Fatal("expected error file")
+	}
+	ef := result.ErrorFile.GetExcelizeFile()
+	if got, _ := ef.GetCellValue("Sheet1", "A2"); got != wantDate || got == "45292" {
+		t.Errorf("expected formatted date %q, got %q", wantDate, got)
+	}
+	if typ, _ := ef.GetCellType("Sheet1", "B2"); typ == excelize.CellTypeSharedString || typ == excelize.CellTypeInlineString {
+		t.Errorf("expected number cell for amount, got type %v", typ)
+	}
+	if got, _ := ef.GetCellValue("Sheet1", "C2"); got != "00123" {
+		t.Errorf("expected text code kept, got %q", got)
+	}
+	if got, _ := ef.GetCellValue("Sheet1", "D2"); got != "abc" {
+		t.Errorf("expected invalid value kept, got %q", got)
+	}
+}
@@ -0,0 +1,118 @@
+package excel
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	FieldTag       = "excel"
+	FieldTagTitle  = "title"
+	FieldTagOrder  = "order"
+	FieldTagWidth  = "width"
+	FieldTagFormat = "format"
+	FieldTagIgnore = "ignore"
+)
+
+// FieldMeta 结构体字段与 excel 列的映射信息
+type FieldMeta struct {
+	Field  reflect.StructField
+	Key    string  // 校验函数使用的键，为字段名
+	Title  string  // 标题，默认为字段名
+	Order  int     // 列顺序，升序排列，未指定的列按声明顺序排在最后
+	Width  float64 // 列宽，0 为默认宽度
+	Format string  // 数字或日期格式，如 0.00、yyyy-mm-dd
+	Index  int     // 在 struct 中的索引
+}
+
+var fieldMetaCache sync.Map // map[reflect.Type][]FieldMeta
+
+// getFieldMetas 获取结构体字段元信息（带缓存），跳过非导出字段与 ignore 字段，按列顺序排列
+func getFieldMetas(t reflect.Type) []FieldMeta {
+	if metas, ok := fieldMetaCache.Load(t); ok {
+		return metas.([]FieldMeta)
+	}
+
+	var metas []FieldMeta
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" { // 非导出字段跳过
+			continue
+		}
+		meta, ignore := parseExcelTag(sf)
+		if ignore {
+			continue
+		}
+		meta.Index = i
+		metas = append(metas, meta)
+	}
+	sort.SliceStable(metas, func(i, j int) bool {
+		return metas[i].Order < metas[j].Order
+	})
+
+	fieldMetaCache.Store(t, metas)
+	return metas
+}
+
+// GetFieldMetas 获取结构体字段元信息，非结构体类型返回 nil
+func GetFieldMetas(t reflect.Type) []FieldMeta {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return getFieldMetas(t)
+}
+
+// parseExcelTag 解析 excel:"..." 标签，返回字段是否忽略
+func parseExcelTag(sf reflect.StructField) (FieldMeta, bool) {
+	tag := sf.Tag.Get(FieldTag)
+	meta := FieldMeta{
+		Field: sf,
+		Key:   sf.Name,
+		Title: sf.Name,
+		Order: math.MaxInt,
+	}
+	if tag == "-" {
+		return meta, true
+	}
+
+	for _, part := range strings.Split(tag, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+
+		if len(kv) == 1 {
+			if key == FieldTagIgnore {
+				return meta, true
+			}
+			continue
+		}
+
+		val := strings.TrimSpace(kv[1])
+		switch key {
+		case FieldTagTitle:
+			if val != "" {
+				meta.Title = val
+			}
+		case FieldTagOrder:
+			if order, err := strconv.Atoi(val); err == nil {
+				meta.Order = order
+			}
+		case FieldTagWidth:
+			if width, err := strconv.ParseFloat(val, 64); err == nil {
+				meta.Width = width
+			}
+		case FieldTagFormat:
+			meta.Format = val
+		}
+	}
+	return meta, false
+}
@@ -0,0 +1,101 @@
+package excel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// 输出文件的 Content-Type
+const (
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	ContentTypeZIP  = "application/zip"
+	ContentTypeCSV  = "text/csv"
+	ContentTypeTSV  = "text/tab-separated-values"
+)
+
+// WriteResponse 将数据作为附件写入 HTTP 响应，设置 Content-Type 与 Content-Disposition（文件名按 RFC 5987 编码，支持中文），
+// 文件名见 OutputName。客户端断开连接（请求的 context 结束）时停止写入并返回 context 的错误
+//
+// 参数:
+//   - w: HTTP 响应
+//   - r: HTTP 请求
+//
+// 返回:
+//   - error: 写入失败或客户端断开连接的错误信息
+func (f *File) WriteResponse(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", f.contentType())
+	w.Header().Set("Content-Disposition", contentDisposition(f.OutputName()))
+	if _, err := f.WriteTo(&contextWriter{ctx: ctx, w: w}); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("client disconnected: %w", ctxErr)
+		}
+		return fmt.Errorf("write response failed: %w", err)
+	}
+	return nil
+}
+
+// contentType 输出文件的 Content-Type
+func (f *File) contentType() string {
+	if f.archived() {
+		return ContentTypeZIP
+	}
+	if w, ok := f.writer.(*csvWriter); ok {
+		contentType := ContentTypeCSV
+		if w.opts.Delimiter == '\t' {
+			contentType = ContentTypeTSV
+		}
+		// 指定了其他字符编码时无法确定 charset 名称，交由客户端识别
+		if w.opts.Encoding == nil {
+			contentType += "; charset=utf-8"
+		}
+		return contentType
+	}
+	return ContentTypeXLSX
+}
+
+// contentDisposition 生成附件的 Content-Disposition：filename 为 ASCII 回退名称，filename* 为 RFC 5987 编码的 UTF-8 文件名
+func contentDisposition(fileName string) string {
+	fileName = filepath.Base(fileName)
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, fileName)
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, encodeRFC5987(fileName))
+}
+
+// encodeRFC5987 按 RFC 5987 的 attr-char 对值进行百分号编码
+func encodeRFC5987(value string) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			strings.IndexByte("!#$&+-.^_`|~", c) >= 0:
+			b.WriteByte(c)
+		default:
+			_, _ = fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// contextWriter 在 context 结束后拒绝写入，用于在客户端断开连接时停止输出
+type contextWriter struct {
+	ctx context.Context
+	w   http.ResponseWriter
+}
+
+func (c *contextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
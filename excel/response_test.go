@@ -0,0 +1,61 @@
+package excel
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteResponse(t *testing.T) {
+	f := NewFile("销售报表 2024.xlsx")
+	if err := f.ExportFromDataMap("Sheet1", []string{"编号"}, []string{"id"}, []map[string]any{{"id": 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := f.WriteResponse(rec, httptest.NewRequest("GET", "/export", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != ContentTypeXLSX {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	expected := `attachment; filename="____ 2024.xlsx"; filename*=UTF-8''%E9%94%80%E5%94%AE%E6%8A%A5%E8%A1%A8%202024.xlsx`
+	if disposition := rec.Header().Get("Content-Disposition"); disposition != expected {
+		t.Errorf("expected disposition %q, got %q", expected, disposition)
+	}
+	book, err := excelize.OpenReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer book.Close()
+	if value, _ := book.GetCellValue("Sheet1", "A2"); value != "1" {
+		t.Errorf("unexpected cell value %q", value)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = httptest.NewRecorder()
+	err = f.WriteResponse(rec, httptest.NewRequest("GET", "/export", nil).WithContext(ctx))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected nothing written after disconnect, got %d bytes", rec.Body.Len())
+	}
+}
+
+func TestCSVResponseContentType(t *testing.T) {
+	f := NewCSVFile("users.csv", CSVOptions{})
+	defer f.Close()
+	for _, sheet := range []string{"a", "b"} {
+		if err := f.ExportFromDataMap(sheet, []string{"编号"}, []string{"id"}, []map[string]any{{"id": 1}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if name, contentType := f.OutputName(), f.contentType(); name != "users.zip" || contentType != ContentTypeZIP {
+		t.Errorf("expected zip output for multiple sheets, got %s %s", name, contentType)
+	}
+}
@@ -0,0 +1,349 @@
+package excel
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Cooooing/cutil/base/logger"
+	"github.com/xuri/excelize/v2"
+)
+
+// SetSheetMaxRows 开启流式导出的工作表滚动：工作表行数（含标题行与汇总行）达到上限时，
+// 继续写入 sheetName_2、sheetName_3 ...，每个工作表重复标题行、列宽与样式
+//
+// 参数:
+//   - maxRows: 每个工作表的最大行数，小于等于 0 或超过 xlsx 上限时为 xlsx 上限 1048576
+func (f *File) SetSheetMaxRows(maxRows int) {
+	if maxRows <= 0 || maxRows > excelize.TotalRows {
+		maxRows = excelize.TotalRows
+	}
+	f.sheetMaxRows = maxRows
+}
+
+// SetSplitFile 开启流式导出的工作簿拆分：单个工作簿的数据行数或数据量达到上限时，保存当前工作簿并写入新的工作簿。
+// 拆分后 WriteToFile 输出包含全部工作簿的 zip 文件（文件名为 fileName 替换扩展名为 .zip），工作簿命名为 fileName_1.xlsx ...
+//
+// 参数:
+//   - maxRows: 每个工作簿的最大数据行数（不含标题行），0 为不限制
+//   - maxSize: 每个工作簿的最大数据量（单元格文本的字节数，近似未压缩大小），0 为不限制
+func (f *File) SetSplitFile(maxRows int, maxSize int64) {
+	f.splitRows, f.splitSize = maxRows, maxSize
+}
+
+// sheetWriter 流式写入工作表，按配置滚动工作表与拆分工作簿
+type sheetWriter struct {
+	f      *File
+	base   string // 首个工作表名称
+	layout *layout
+
+	sheetName string
+	sw        *excelize.StreamWriter
+	started   bool             // 是否已写入标题行
+	pending   []map[string]any // 自动列宽采样期间缓存的数据行
+	rowIndex  int              // 当前工作表最后写入的行，不含缓存的数据行
+	sheets    int              // 当前工作簿中已写入的工作表数
+	fileRows  int              // 当前工作簿已写入的数据行数
+	fileSize  int64            // 当前工作簿已写入的数据量
+}
+
+// newSheetWriter 创建流式写入器，创建工作表并写入标题行
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - titles: 标题行
+//   - keys: 数据行对应的键
+//   - formats: 各列默认格式，可为空
+//   - widths: 各列默认列宽，可为空
+//
+// 返回:
+//   - *sheetWriter: 流式写入器
+//   - error: 创建失败的错误信息
+func (f *File) newSheetWriter(sheetName string, titles []string, keys []string, formats []string, widths []float64) (*sheetWriter, error) {
+	if err := f.checkParams(sheetName, titles, keys); err != nil {
+		return nil, err
+	}
+	l, err := f.newLayout(sheetName, titles, keys, formats, widths)
+	if err != nil {
+		return nil, err
+	}
+	w := &sheetWriter{f: f, base: sheetName, layout: l}
+	if err := w.open(sheetName); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open 创建工作表。需要自动列宽时先缓存采样的数据行，采样结束后再设置列宽并写入标题行
+func (w *sheetWriter) open(sheetName string) error {
+	l := w.layout
+	if err := w.f.checkParams(sheetName, l.titles, l.keys); err != nil {
+		return err
+	}
+	w.sheetName, w.sw, w.started, w.rowIndex = sheetName, nil, false, l.headerRows()
+	w.sheets++
+	if w.f.writer == nil {
+		sw, err := w.f.file.NewStreamWriter(sheetName)
+		if err != nil {
+			return err
+		}
+		w.sw = sw
+	}
+	if w.f.writer != nil || !l.sampling() {
+		return w.start()
+	}
+	return nil
+}
+
+// start 设置列宽与冻结窗格，写入表头与缓存的数据行
+func (w *sheetWriter) start() error {
+	w.started = true
+	if w.sw != nil {
+		// 流式写入时列宽与冻结窗格必须在写入行之前设置
+		if err := applyStreamLayout(w.sw, w.layout); err != nil {
+			return err
+		}
+	}
+	if err := w.f.writeHeader(w.sheetName, w.layout, w.sw); err != nil {
+		return err
+	}
+	pending := w.pending
+	w.pending = nil
+	for _, row := range pending {
+		if err := w.writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write 写入一行数据，达到工作簿上限时拆分工作簿，达到工作表上限时滚动到下一个工作表（仅 xlsx）
+func (w *sheetWriter) write(row map[string]any) error {
+	f := w.f
+	switch {
+	case f.writer != nil:
+		// csv 等格式没有行数上限，不滚动与拆分
+	case (f.splitRows > 0 && w.fileRows >= f.splitRows) || (f.splitSize > 0 && w.fileSize >= f.splitSize):
+		if err := w.split(); err != nil {
+			return err
+		}
+	// 工作表上限预留小计行与合计行
+	case f.sheetMaxRows > 0 && w.rowIndex+len(w.pending)+w.layout.summary.reserved() >= f.sheetMaxRows:
+		if err := w.finish(); err != nil {
+			return err
+		}
+		if err := w.open(rolloverSheetName(w.base, w.sheets+1)); err != nil {
+			return err
+		}
+	}
+
+	w.fileRows++
+	if f.splitSize > 0 {
+		for _, key := range w.layout.keys {
+			if value := row[key]; value != nil {
+				w.fileSize += int64(len(fmt.Sprint(value)))
+			}
+		}
+	}
+	if !w.started {
+		w.layout.observe(row)
+		w.pending = append(w.pending, row)
+		if !w.layout.sampling() {
+			return w.start()
+		}
+		return nil
+	}
+	return w.writeRow(row)
+}
+
+// writeRow 在最后写入的行之后写入一行数据
+func (w *sheetWriter) writeRow(row map[string]any) (err error) {
+	w.rowIndex, err = w.f.writeLayoutRow(w.sheetName, w.layout, w.rowIndex+1, row, w.sw)
+	return err
+}
+
+// finish 结束当前工作表：写入缓存的数据行，合并剩余的相同值区域，写入小计行与合计行，设置自动筛选与条件格式并结束流式写入
+func (w *sheetWriter) finish() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	if w.sw == nil {
+		return nil
+	}
+	// 合并单元格、自动筛选与条件格式写入流式写入器关联的工作表，须在 Flush 之前设置
+	if err := w.f.finishSheet(w.sheetName, w.layout, w.rowIndex, w.sw); err != nil {
+		return err
+	}
+	return w.sw.Flush()
+}
+
+// split 保存当前工作簿，在新的工作簿中从首个工作表继续写入
+func (w *sheetWriter) split() error {
+	if err := w.finish(); err != nil {
+		return err
+	}
+	if err := w.f.newPart(w.layout); err != nil {
+		return err
+	}
+	w.sheets, w.fileRows, w.fileSize = 0, 0, 0
+	if err := w.open(w.base); err != nil {
+		return err
+	}
+	// 删除新工作簿默认的工作表
+	if defaultSheet := w.f.file.GetSheetName(0); defaultSheet != w.base {
+		if err := w.f.file.DeleteSheet(defaultSheet); err != nil {
+			return fmt.Errorf("delete default sheet failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// close 结束流式写入
+func (w *sheetWriter) close() error {
+	return w.finish()
+}
+
+// rolloverSheetName 滚动工作表名称，超出工作表名称长度上限时截断原名称
+func rolloverSheetName(base string, n int) string {
+	suffix := fmt.Sprintf("_%d", n)
+	if utf8.RuneCountInString(base)+len(suffix) > excelize.MaxSheetNameLength {
+		base = string([]rune(base)[:excelize.MaxSheetNameLength-len(suffix)])
+	}
+	return base + suffix
+}
+
+// newPart 将当前工作簿保存为临时文件，并创建新的工作簿，重建错误样式、标题样式与列布局的样式。
+// 样式映射完成后关闭原工作簿，释放其流式写入的临时文件
+//
+// 参数:
+//   - l: 继续写入的列布局，其样式映射为新工作簿中相同的样式
+//
+// 返回:
+//   - error: 保存或创建失败的错误信息
+func (f *File) newPart(l *layout) error {
+	tmp, err := os.CreateTemp("", "excel-part-*.xlsx")
+	if err != nil {
+		return fmt.Errorf("create temp file failed: %w", err)
+	}
+	f.parts = append(f.parts, tmp.Name())
+	if err := f.file.Write(tmp); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write part failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	old := f.file
+	defer func() {
+		if err := old.Close(); err != nil {
+			logger.Error("close part workbook error: %w", err)
+		}
+	}()
+	f.file = excelize.NewFile()
+	f.cellStyles, f.errorStyles = nil, nil
+	if err := f.SetErrorStyle(f.errorStyle); err != nil {
+		return err
+	}
+	if f.titleStyle != nil {
+		if err := f.SetTitleStyle(f.titleStyle); err != nil {
+			return err
+		}
+	}
+
+	styles := make(map[int]int)
+	remap := func(styleId int) (int, error) {
+		if styleId == 0 {
+			return 0, nil
+		}
+		if newId, ok := styles[styleId]; ok {
+			return newId, nil
+		}
+		style, err := old.GetStyle(styleId)
+		if err != nil {
+			return 0, fmt.Errorf("get style %d failed: %w", styleId, err)
+		}
+		newId, err := f.file.NewStyle(style)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create style: %w", err)
+		}
+		styles[styleId] = newId
+		return newId, nil
+	}
+	return l.remap(remap)
+}
+
+// partName 拆分后第 n 个工作簿的文件名
+func (f *File) partName(n int) string {
+	ext := filepath.Ext(f.fileName)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(f.fileName, ext), n, ext)
+}
+
+// zipName 拆分后 zip 文件的文件名
+func (f *File) zipName() string {
+	return strings.TrimSuffix(f.fileName, filepath.Ext(f.fileName)) + ".zip"
+}
+
+// writeZip 将拆分的全部工作簿写入 zip
+func (f *File) writeZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for i, part := range f.parts {
+		entry, err := zw.Create(f.partName(i + 1))
+		if err != nil {
+			return err
+		}
+		if err := copyFile(entry, part); err != nil {
+			return err
+		}
+	}
+	entry, err := zw.Create(f.partName(len(f.parts) + 1))
+	if err != nil {
+		return err
+	}
+	if err := f.file.Write(entry); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) {
+		if err := file.Close(); err != nil {
+			logger.Error("close file error: %w", err)
+		}
+	}(file)
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// Close 关闭文件，释放读取、拆分工作簿与 csv 写入时产生的临时文件。
+// 导出完成（WriteTo、WriteToFile 等）后必须调用 Close，否则拆分工作簿与流式写入的临时文件不会被删除
+func (f *File) Close() error {
+	f.removeParts()
+	if f.writer != nil {
+		if err := f.writer.Close(); err != nil {
+			return err
+		}
+	}
+	return f.file.Close()
+}
+
+// removeParts 删除拆分工作簿的临时文件
+func (f *File) removeParts() {
+	for _, part := range f.parts {
+		if err := os.Remove(part); err != nil && !os.IsNotExist(err) {
+			logger.Error("remove part file error: %w", err)
+		}
+	}
+	f.parts = nil
+}
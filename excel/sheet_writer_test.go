@@ -0,0 +1,110 @@
+package excel
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSheetRollover(t *testing.T) {
+	f := NewFile("rollover.xlsx")
+	if err := f.SetTitleStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		t.Fatal(err)
+	}
+	f.SetSheetMaxRows(10)
+
+	titles := []string{"id", "姓名"}
+	keys := []string{"id", "name"}
+	values := make([]map[string]any, 25)
+	for i := range values {
+		values[i] = map[string]any{"id": i + 1, "name": fmt.Sprintf("user%d", i+1)}
+	}
+	if err := f.ExportStreamFromDataMap("Data", titles, keys, values); err != nil {
+		t.Fatal(err)
+	}
+
+	ef := f.GetExcelizeFile()
+	expected := map[string]int{"Data": 10, "Data_2": 10, "Data_3": 8}
+	for sheet, count := range expected {
+		rows, err := ef.GetRows(sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != count || rows[0][1] != "姓名" {
+			t.Errorf("sheet %s: expected %d rows with title, got %v", sheet, count, rows)
+		}
+		if style, _ := ef.GetCellStyle(sheet, "A1"); style != *f.titleStyleId {
+			t.Errorf("sheet %s: expected title style", sheet)
+		}
+	}
+	if value, _ := ef.GetCellValue("Data_3", "A8"); value != "25" {
+		t.Errorf("expected last row 25, got %s", value)
+	}
+	if name := rolloverSheetName("一二三四五六七八九十一二三四五六七八九十一二三四五六七八九十", 12); len([]rune(name)) != excelize.MaxSheetNameLength {
+		t.Errorf("expected sheet name truncated, got %s", name)
+	}
+}
+
+func TestSplitFile(t *testing.T) {
+	f := NewFile("split.xlsx")
+	f.SetSplitFile(10, 0)
+
+	users := make([]ExportUser, 25)
+	for i := range users {
+		users[i] = ExportUser{Id: int64(i + 1), Name: "user", Salary: 1.5}
+	}
+	if err := ExportStreamFromStructs(f, "Data", users); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := f.WriteToFile(dir); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.OpenReader(filepath.Join(dir, "split.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if len(reader.File) != 3 {
+		t.Fatalf("expected 3 workbooks, got %d", len(reader.File))
+	}
+	counts := []int{11, 11, 6}
+	for i, entry := range reader.File {
+		if entry.Name != fmt.Sprintf("split_%d.xlsx", i+1) {
+			t.Errorf("unexpected entry name %s", entry.Name)
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		part, err := excelize.OpenReader(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, _ := part.GetRows("Data")
+		if len(rows) != counts[i] || rows[1][3] != "1.50" {
+			t.Errorf("workbook %d: expected %d rows with formatted salary, got %v", i+1, counts[i], rows)
+		}
+		if sheets := part.GetSheetList(); len(sheets) != 1 && i > 0 {
+			t.Errorf("workbook %d: unexpected sheets %v", i+1, sheets)
+		}
+		if width, _ := part.GetColWidth("Data", "B"); width != 20 {
+			t.Errorf("workbook %d: expected column B width 20, got %v", i+1, width)
+		}
+		_ = part.Close()
+	}
+
+	parts := append([]string(nil), f.parts...)
+	_ = f.Close()
+	for _, part := range parts {
+		if _, err := os.Stat(part); !os.IsNotExist(err) {
+			t.Errorf("expected part %s removed", part)
+		}
+	}
+}
@@ -0,0 +1,499 @@
+package excel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/width"
+)
+
+const (
+	defaultSampleRows = 100 // 自动列宽默认采样的数据行数
+	defaultMinWidth   = 8   // 自动列宽默认最小值
+	defaultMaxWidth   = 60  // 自动列宽默认最大值
+)
+
+// ColumnOptions 列展示选项
+type ColumnOptions struct {
+	Width    float64 // 固定列宽，优先于自动列宽
+	AutoFit  bool    // 按标题与采样数据自动计算列宽，中日韩等全角字符按两个字符计算
+	MinWidth float64 // 自动列宽的最小值，默认 8
+	MaxWidth float64 // 自动列宽的最大值，默认 60
+	Format   string  // 数字或日期格式，如 #,##0.00、yyyy-mm-dd，优先于结构体标签
+	Align    string  // 水平对齐：left、center、right
+	VAlign   string  // 垂直对齐：top、center、bottom
+	Wrap     bool    // 自动换行
+
+	Aggregate Aggregate // 汇总方式，在数据末尾写入合计行，设置 SheetOptions.GroupBy 时同时写入小计行
+}
+
+// ConditionalFormat 条件格式规则，应用于列的全部数据行
+type ConditionalFormat struct {
+	Key     string                            // 应用的列对应的键
+	Style   *excelize.Style                   // 满足条件时的样式，仅字体、填充、边框生效
+	Options excelize.ConditionalFormatOptions // 条件，如 {Type: "cell", Criteria: ">", Value: "100"}，Format 由 Style 生成
+}
+
+// SheetOptions 工作表展示选项
+type SheetOptions struct {
+	Columns    map[string]ColumnOptions // 数据键 -> 列选项
+	FreezeRows int                      // 冻结的行数，如 1 冻结标题行
+	FreezeCols int                      // 冻结的列数
+	AutoFilter bool                     // 标题行开启自动筛选
+	ZebraColor string                   // 斑马纹背景色，应用于偶数数据行，如 #F2F2F2
+	Conditions []ConditionalFormat      // 条件格式
+	SampleRows int                      // 自动列宽采样的数据行数，默认 100
+	Headers    []Header                 // 多级表头，设置后替代导出函数的标题与键，数据列为叶子节点
+	MergeKeys  []string                 // 合并连续相同值的列，见 MergeSameValues
+
+	GroupBy       string // 分组键，值变化时写入小计行，需要至少一列设置 ColumnOptions.Aggregate
+	TotalLabel    string // 合计行标签，写入第一个不汇总的列，默认为 合计
+	SubtotalLabel string // 小计行标签，前面加上分组的值，默认为 小计
+}
+
+// SetSheetOptions 设置工作表的展示选项，在导出该工作表之前设置。滚动产生的工作表沿用原工作表的选项
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - opts: 展示选项
+func (f *File) SetSheetOptions(sheetName string, opts SheetOptions) {
+	if f.sheetOptions == nil {
+		f.sheetOptions = make(map[string]SheetOptions)
+	}
+	f.sheetOptions[sheetName] = opts
+}
+
+// cellStyle 单元格样式定义，用作样式缓存的键
+type cellStyle struct {
+	format string
+	align  string
+	valign string
+	wrap   bool
+	fill   string
+	bold   bool
+}
+
+// styleFor 获取单元格样式定义对应的样式（带缓存），空定义返回 0
+func (f *File) styleFor(cs cellStyle) (int, error) {
+	if cs == (cellStyle{}) {
+		return 0, nil
+	}
+	if styleId, ok := f.cellStyles[cs]; ok {
+		return styleId, nil
+	}
+	style := &excelize.Style{}
+	if cs.format != "" {
+		style.CustomNumFmt = &cs.format
+	}
+	if cs.align != "" || cs.valign != "" || cs.wrap {
+		style.Alignment = &excelize.Alignment{Horizontal: cs.align, Vertical: cs.valign, WrapText: cs.wrap}
+	}
+	if cs.fill != "" {
+		style.Fill = excelize.Fill{Type: "pattern", Color: []string{cs.fill}, Pattern: 1}
+	}
+	if cs.bold {
+		style.Font = &excelize.Font{Bold: true}
+	}
+	styleId, err := f.file.NewStyle(style)
+	if err != nil {
+		return 0, fmt.Errorf("create column style failed: %w", err)
+	}
+	if f.cellStyles == nil {
+		f.cellStyles = make(map[cellStyle]int)
+	}
+	f.cellStyles[cs] = styleId
+	return styleId, nil
+}
+
+// errorStyleFor 获取校验失败单元格的样式：在错误样式的基础上保留列样式的数字格式与对齐（带缓存）
+func (f *File) errorStyleFor(styleId int) (int, error) {
+	if styleId == 0 || f.errorStyle == nil {
+		return *f.errorStyleId, nil
+	}
+	if errorStyleId, ok := f.errorStyles[styleId]; ok {
+		return errorStyleId, nil
+	}
+	columnStyle, err := f.file.GetStyle(styleId)
+	if err != nil {
+		return 0, fmt.Errorf("get style %d failed: %w", styleId, err)
+	}
+	style := *f.errorStyle
+	style.NumFmt, style.CustomNumFmt = columnStyle.NumFmt, columnStyle.CustomNumFmt
+	if style.Alignment == nil {
+		style.Alignment = columnStyle.Alignment
+	}
+	errorStyleId, err := f.file.NewStyle(&style)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create style: %w", err)
+	}
+	if f.errorStyles == nil {
+		f.errorStyles = make(map[int]int)
+	}
+	f.errorStyles[styleId] = errorStyleId
+	return errorStyleId, nil
+}
+
+// layout 工作表的列布局：合并结构体标签与工作表选项得到的表头、列样式、列宽与工作表规则
+type layout struct {
+	titles []string
+	keys   []string
+	opts   SheetOptions
+
+	header       [][]string // 表头各行
+	headerMerges []string   // 表头合并区域
+	mergeIndex   []int      // 合并相同值的列索引
+	runStart     []int      // 各合并列当前区域的起始行，0 为未开始
+	runValue     []string   // 各合并列当前区域的值
+	summary      *summary   // 汇总行，未设置汇总列时为空
+
+	styles   []int     // 数据行样式
+	zebra    []int     // 斑马纹行样式，未开启时为空
+	widths   []float64 // 固定列宽，0 为未指定
+	autoFit  []bool
+	measured []float64 // 自动列宽列的内容宽度
+	sampled  int
+}
+
+// newLayout 创建工作表的列布局
+//
+// 参数:
+//   - sheetName: 工作表名称，用于查找工作表选项
+//   - titles: 标题行，设置多级表头时由表头替代
+//   - keys: 数据行对应的键，设置多级表头时由表头替代
+//   - formats: 各列默认格式（来自结构体标签，与 keys 对应），可为空
+//   - widths: 各列默认列宽（来自结构体标签，与 keys 对应），可为空
+//
+// 返回:
+//   - *layout: 列布局
+//   - error: 表头定义错误或创建样式失败的错误信息
+func (f *File) newLayout(sheetName string, titles []string, keys []string, formats []string, widths []float64) (*layout, error) {
+	opts := f.sheetOptions[sheetName]
+	defaults := make(map[string]int, len(keys))
+	for i, key := range keys {
+		defaults[key] = i
+	}
+	header, headerMerges := [][]string{titles}, []string(nil)
+	if len(opts.Headers) > 0 {
+		var err error
+		if header, headerMerges, titles, keys, err = buildHeader(opts.Headers); err != nil {
+			return nil, err
+		}
+	}
+
+	l := &layout{
+		titles:       titles,
+		keys:         keys,
+		opts:         opts,
+		header:       header,
+		headerMerges: headerMerges,
+		styles:       make([]int, len(keys)),
+		widths:       make([]float64, len(keys)),
+		autoFit:      make([]bool, len(keys)),
+		measured:     make([]float64, len(keys)),
+	}
+	if l.opts.ZebraColor != "" {
+		l.zebra = make([]int, len(keys))
+	}
+	columnStyles := make([]cellStyle, len(keys))
+	for i, key := range keys {
+		col := l.opts.Columns[key]
+		cs := cellStyle{format: col.Format, align: col.Align, valign: col.VAlign, wrap: col.Wrap}
+		d, ok := defaults[key]
+		if cs.format == "" && ok && d < len(formats) {
+			cs.format = formats[d]
+		}
+		columnStyles[i] = cs
+		var err error
+		if l.styles[i], err = f.styleFor(cs); err != nil {
+			return nil, err
+		}
+		if l.zebra != nil {
+			cs.fill = l.opts.ZebraColor
+			if l.zebra[i], err = f.styleFor(cs); err != nil {
+				return nil, err
+			}
+		}
+
+		l.widths[i] = col.Width
+		if l.widths[i] <= 0 && ok && d < len(widths) {
+			l.widths[i] = widths[d]
+		}
+		l.autoFit[i] = col.AutoFit && l.widths[i] <= 0
+		if l.autoFit[i] && i < len(titles) {
+			l.measured[i] = textWidth(titles[i])
+		}
+	}
+
+	for _, key := range l.opts.MergeKeys {
+		index := slices.Index(keys, key)
+		if index == -1 {
+			return nil, fmt.Errorf("merge key %s not exists", key)
+		}
+		l.mergeIndex = append(l.mergeIndex, index)
+	}
+	l.runStart = make([]int, len(l.mergeIndex))
+	l.runValue = make([]string, len(l.mergeIndex))
+
+	var err error
+	if l.summary, err = f.newSummary(l, columnStyles); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// headerRows 返回表头行数
+func (l *layout) headerRows() int {
+	return len(l.header)
+}
+
+// rowStyles 返回数据行的各列样式
+func (l *layout) rowStyles(rowIndex int) []int {
+	if l.zebra != nil && (rowIndex-l.headerRows())%2 == 0 {
+		return l.zebra
+	}
+	return l.styles
+}
+
+// sampling 返回是否仍需采样数据以计算自动列宽
+func (l *layout) sampling() bool {
+	sampleRows := l.opts.SampleRows
+	if sampleRows <= 0 {
+		sampleRows = defaultSampleRows
+	}
+	if l.sampled >= sampleRows {
+		return false
+	}
+	for _, autoFit := range l.autoFit {
+		if autoFit {
+			return true
+		}
+	}
+	return false
+}
+
+// observe 采样数据行，记录自动列宽列的内容宽度
+func (l *layout) observe(row map[string]any) {
+	if !l.sampling() {
+		return
+	}
+	l.sampled++
+	for i, key := range l.keys {
+		if l.autoFit[i] {
+			l.measured[i] = max(l.measured[i], textWidth(cellText(row[key])))
+		}
+	}
+}
+
+// columnWidths 返回各列最终列宽，0 为默认列宽
+func (l *layout) columnWidths() []float64 {
+	widths := make([]float64, len(l.keys))
+	for i, key := range l.keys {
+		if !l.autoFit[i] {
+			widths[i] = l.widths[i]
+			continue
+		}
+		col := l.opts.Columns[key]
+		minWidth, maxWidth := col.MinWidth, col.MaxWidth
+		if minWidth <= 0 {
+			minWidth = defaultMinWidth
+		}
+		if maxWidth <= 0 {
+			maxWidth = defaultMaxWidth
+		}
+		widths[i] = min(max(l.measured[i]+2, minWidth), maxWidth)
+	}
+	return widths
+}
+
+// panes 返回冻结窗格设置，未冻结时返回 nil
+func (l *layout) panes() *excelize.Panes {
+	if l.opts.FreezeRows <= 0 && l.opts.FreezeCols <= 0 {
+		return nil
+	}
+	topLeft, _ := excelize.CoordinatesToCellName(l.opts.FreezeCols+1, l.opts.FreezeRows+1)
+	return &excelize.Panes{
+		Freeze:      true,
+		XSplit:      l.opts.FreezeCols,
+		YSplit:      l.opts.FreezeRows,
+		TopLeftCell: topLeft,
+		ActivePane:  "bottomRight",
+	}
+}
+
+// remap 将样式映射为拆分后新工作簿中的样式
+func (l *layout) remap(remap func(int) (int, error)) error {
+	var err error
+	for i := range l.styles {
+		if l.styles[i], err = remap(l.styles[i]); err != nil {
+			return err
+		}
+		if l.zebra != nil {
+			if l.zebra[i], err = remap(l.zebra[i]); err != nil {
+				return err
+			}
+		}
+		if l.summary != nil {
+			if l.summary.styles[i], err = remap(l.summary.styles[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeLayoutRow 按列布局写入一行数据：分组键变化时先写入小计行，并合并已结束的相同值区域（支持流式/非流式）
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - l: 列布局
+//   - rowIndex: 写入的行号
+//   - row: 数据行
+//   - sw: 流式写入器
+//
+// 返回:
+//   - int: 最后写入的行号
+//   - error: 写入失败的错误信息
+func (f *File) writeLayoutRow(sheetName string, l *layout, rowIndex int, row map[string]any, sw *excelize.StreamWriter) (int, error) {
+	if s := l.summary; s != nil && s.groupIndex >= 0 && f.writer == nil {
+		value := cellText(row[l.keys[s.groupIndex]])
+		if s.groupStart != 0 && value != s.groupValue {
+			if err := f.writeSubtotal(sheetName, l, rowIndex, sw); err != nil {
+				return 0, err
+			}
+			rowIndex++
+		}
+		if s.groupStart == 0 {
+			s.groupStart, s.groupValue = rowIndex, value
+		}
+	}
+	if err := f.writeRow(sheetName, rowIndex, l.keys, row, l.rowStyles(rowIndex), sw); err != nil {
+		return 0, fmt.Errorf("write row %d err: %w", rowIndex, err)
+	}
+	return rowIndex, f.mergeCells(sheetName, l.trackMerges(rowIndex, row), sw)
+}
+
+// finishSheet 结束工作表：合并剩余的相同值区域，写入小计行与合计行，非流式时设置列宽与冻结窗格，并设置自动筛选与条件格式。
+// 流式写入时须在 Flush 之前调用
+func (f *File) finishSheet(sheetName string, l *layout, lastRow int, sw *excelize.StreamWriter) error {
+	if err := f.mergeCells(sheetName, l.flushMerges(lastRow), sw); err != nil {
+		return err
+	}
+	// 自动筛选与条件格式不包含合计行
+	lastRow, err := f.writeSummary(sheetName, l, lastRow, sw)
+	if err != nil {
+		return err
+	}
+	if sw == nil {
+		if err := f.applyLayout(sheetName, l); err != nil {
+			return err
+		}
+	}
+	return f.applyRules(sheetName, l, lastRow)
+}
+
+// applyLayout 设置列宽与冻结窗格（非流式，写入数据之后调用）
+func (f *File) applyLayout(sheetName string, l *layout) error {
+	if f.writer != nil {
+		return nil
+	}
+	for i, w := range l.columnWidths() {
+		if w <= 0 {
+			continue
+		}
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		if err := f.file.SetColWidth(sheetName, col, col, w); err != nil {
+			return fmt.Errorf("set column width failed: %w", err)
+		}
+	}
+	if panes := l.panes(); panes != nil {
+		if err := f.file.SetPanes(sheetName, panes); err != nil {
+			return fmt.Errorf("set panes failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyStreamLayout 设置列宽与冻结窗格（流式，写入任何行之前调用）
+func applyStreamLayout(sw *excelize.StreamWriter, l *layout) error {
+	for i, w := range l.columnWidths() {
+		if w <= 0 {
+			continue
+		}
+		if err := sw.SetColWidth(i+1, i+1, w); err != nil {
+			return fmt.Errorf("set column width failed: %w", err)
+		}
+	}
+	if panes := l.panes(); panes != nil {
+		if err := sw.SetPanes(panes); err != nil {
+			return fmt.Errorf("set panes failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyRules 设置自动筛选与条件格式。流式写入时须在 Flush 之前调用
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - l: 列布局
+//   - lastRow: 最后一行的行号
+//
+// 返回:
+//   - error: 设置失败的错误信息
+func (f *File) applyRules(sheetName string, l *layout, lastRow int) error {
+	if f.writer != nil || len(l.keys) == 0 {
+		return nil
+	}
+	headerRows := l.headerRows()
+	if l.opts.AutoFilter {
+		// 多级表头时筛选最后一行表头
+		firstCell, _ := excelize.CoordinatesToCellName(1, headerRows)
+		lastCell, _ := excelize.CoordinatesToCellName(len(l.keys), max(lastRow, headerRows))
+		if err := f.file.AutoFilter(sheetName, firstCell+":"+lastCell, nil); err != nil {
+			return fmt.Errorf("set auto filter failed: %w", err)
+		}
+	}
+	if lastRow <= headerRows {
+		return nil
+	}
+	for _, cond := range l.opts.Conditions {
+		index := slices.Index(l.keys, cond.Key)
+		if index == -1 {
+			return fmt.Errorf("conditional format key %s not exists", cond.Key)
+		}
+		options := cond.Options
+		if cond.Style != nil {
+			styleId, err := f.file.NewConditionalStyle(cond.Style)
+			if err != nil {
+				return fmt.Errorf("create conditional style failed: %w", err)
+			}
+			options.Format = &styleId
+		}
+		col, _ := excelize.ColumnNumberToName(index + 1)
+		if err := f.file.SetConditionalFormat(sheetName, fmt.Sprintf("%s%d:%s%d", col, headerRows+1, col, lastRow),
+			[]excelize.ConditionalFormatOptions{options}); err != nil {
+			return fmt.Errorf("set conditional format failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// textWidth 计算文本的显示宽度，全角字符计为 2，多行文本取最宽的一行
+func textWidth(text string) float64 {
+	var maxWidth float64
+	for _, line := range strings.Split(text, "\n") {
+		var w float64
+		for _, r := range line {
+			switch width.LookupRune(r).Kind() {
+			case width.EastAsianWide, width.EastAsianFullwidth:
+				w += 2
+			default:
+				w++
+			}
+		}
+		maxWidth = max(maxWidth, w)
+	}
+	return maxWidth
+}
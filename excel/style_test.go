@@ -0,0 +1,118 @@
+package excel
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSheetOptions(t *testing.T) {
+	titles := []string{"编号", "姓名", "金额"}
+	keys := []string{"id", "name", "amount"}
+	data := []map[string]any{
+		{"id": 1, "name": "张三丰", "amount": 120.5},
+		{"id": 2, "name": "Li Si", "amount": 80},
+		{"id": 3, "name": "王五", "amount": 300},
+	}
+	opts := SheetOptions{
+		Columns: map[string]ColumnOptions{
+			"id":     {Width: 6, Align: "center"},
+			"name":   {AutoFit: true, MinWidth: 1, Wrap: true},
+			"amount": {Format: "#,##0.00", Align: "right"},
+		},
+		FreezeRows: 1,
+		AutoFilter: true,
+		ZebraColor: "#F2F2F2",
+		Conditions: []ConditionalFormat{{
+			Key:     "amount",
+			Style:   &excelize.Style{Font: &excelize.Font{Color: "#FF0000"}},
+			Options: excelize.ConditionalFormatOptions{Type: "cell", Criteria: ">", Value: "100"},
+		}},
+	}
+
+	src := NewFile("style.xlsx")
+	for _, stream := range []bool{false, true} {
+		sheet := fmt.Sprintf("stream_%v", stream)
+		src.SetSheetOptions(sheet, opts)
+		var err error
+		if stream {
+			err = src.ExportStreamFromDataMap(sheet, titles, keys, data)
+		} else {
+			err = src.ExportFromDataMap(sheet, titles, keys, data)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := src.GetExcelizeFile().WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, stream := range []bool{false, true} {
+		sheet := fmt.Sprintf("stream_%v", stream)
+		t.Run(sheet, func(t *testing.T) {
+			if width, _ := f.GetColWidth(sheet, "A"); width != 6 {
+				t.Errorf("expected fixed width 6, got %v", width)
+			}
+			// "张三丰" 为 6 个字符宽度，加 2 个字符的边距
+			if width, _ := f.GetColWidth(sheet, "B"); width != 8 {
+				t.Errorf("expected auto fit width 8, got %v", width)
+			}
+			if value, _ := f.GetCellValue(sheet, "C2"); value != "120.50" {
+				t.Errorf("expected formatted amount, got %q", value)
+			}
+			if value, _ := f.GetCellValue(sheet, "B4"); value != "王五" {
+				t.Errorf("expected rows written after sampling, got %q", value)
+			}
+
+			panes, err := f.GetPanes(sheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !panes.Freeze || panes.YSplit != 1 {
+				t.Errorf("expected frozen title row, got %+v", panes)
+			}
+
+			conditions, err := f.GetConditionalFormats(sheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(conditions["C2:C4"]) != 1 {
+				t.Errorf("expected conditional format on C2:C4, got %v", conditions)
+			}
+
+			var filtered bool
+			for _, name := range f.GetDefinedName() {
+				if name.Name == "_xlnm._FilterDatabase" && name.RefersTo == fmt.Sprintf("'%s'!$A$1:$C$4", sheet) {
+					filtered = true
+				}
+			}
+			if !filtered {
+				t.Errorf("expected auto filter on title row")
+			}
+
+			// 偶数数据行（第 3 行）使用斑马纹
+			odd, _ := f.GetCellStyle(sheet, "C2")
+			even, _ := f.GetCellStyle(sheet, "C3")
+			oddStyle, _ := f.GetStyle(odd)
+			evenStyle, _ := f.GetStyle(even)
+			if len(oddStyle.Fill.Color) != 0 || len(evenStyle.Fill.Color) != 1 || evenStyle.Fill.Color[0] != "F2F2F2" {
+				t.Errorf("unexpected zebra fill: %v %v", oddStyle.Fill, evenStyle.Fill)
+			}
+			if evenStyle.Alignment == nil || evenStyle.Alignment.Horizontal != "right" || evenStyle.CustomNumFmt == nil {
+				t.Errorf("expected zebra style keep alignment and format")
+			}
+		})
+	}
+
+	if w := textWidth("ab\n中文字"); w != 6 {
+		t.Errorf("expected text width 6, got %v", w)
+	}
+}
@@ -0,0 +1,357 @@
+package excel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Cooooing/cutil/collections/bitmap"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// RequiredMark 必填列标题的前缀，导入时读取标题会去掉该前缀
+	RequiredMark = "*"
+	// TemplateHelpSheet 模板说明工作表名称
+	TemplateHelpSheet = "填写说明"
+
+	templateOptionsSheet = "_options" // 存放较长下拉选项的隐藏工作表
+	templateDateFormat   = "yyyy-mm-dd"
+	templateListPreview  = 20 // 说明工作表中列出的下拉选项数量上限
+)
+
+// TemplateColumn 导入模板的列定义
+type TemplateColumn struct {
+	Title    string   // 标题
+	Key      string   // 对应的键，用于查找工作表选项中的列选项
+	Required bool     // 必填，标题前加 RequiredMark 并以红色字体显示
+	Options  []string // 下拉选项，可由 EnumOptions 生成。超过 255 个字符或包含逗号时写入隐藏工作表
+	Min      any      // 最小值：int 为整数、float64 为小数、time.Time 为日期，nil 为不限制
+	Max      any      // 最大值，类型同 Min
+	Format   string   // 单元格格式，日期范围默认为 yyyy-mm-dd
+	Note     string   // 填写说明，选中单元格时提示并写入说明工作表
+}
+
+// Template 导入模板：写入标题行与数据校验规则，并生成填写说明工作表，使用户在上传前按规则填写
+type Template struct {
+	*File
+	help    []string // 说明工作表开头的说明文本
+	sheets  int      // 已添加的模板工作表数
+	helpRow int      // 说明工作表最后写入的行
+	options int      // 隐藏选项工作表已使用的列数
+}
+
+// NewTemplate 创建导入模板，通过 AddSheet 添加模板工作表，WriteToFile 输出
+//
+// 参数:
+//   - fileName: 文件名
+//   - help: 说明工作表开头的说明文本，每项一行
+//
+// 返回:
+//   - *Template: 导入模板
+func NewTemplate(fileName string, help ...string) *Template {
+	return &Template{File: NewFile(fileName), help: help}
+}
+
+// EnumOptions 将枚举的名称作为下拉选项，按枚举值升序排列
+//
+// 参数:
+//   - e: 枚举定义
+//
+// 返回:
+//   - []string: 枚举名称
+func EnumOptions[T ~uint64](e *bitmap.Enum[T]) []string {
+	values := slices.Clone(e.Values())
+	slices.Sort(values)
+	options := make([]string, len(values))
+	for i, v := range values {
+		options[i] = e.String(v)
+	}
+	return options
+}
+
+// AddSheet 添加模板工作表：写入标题行，为数据行设置下拉列表与范围校验，并在说明工作表中列出各列的填写要求。
+// 列宽与冻结窗格沿用 SetSheetOptions 设置的工作表选项，模板只有一行标题
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - columns: 列定义
+//
+// 返回:
+//   - error: 列定义错误或写入失败的错误信息
+func (t *Template) AddSheet(sheetName string, columns []TemplateColumn) error {
+	f := t.File
+	titles := make([]string, len(columns))
+	keys := make([]string, len(columns))
+	formats := make([]string, len(columns))
+	for i, column := range columns {
+		if column.Title == "" || column.Key == "" {
+			return fmt.Errorf("template column %d must have title and key", i+1)
+		}
+		titles[i], keys[i], formats[i] = column.Title, column.Key, column.Format
+		if column.Required {
+			titles[i] = RequiredMark + column.Title
+		}
+		if _, ok := column.Min.(time.Time); ok && formats[i] == "" {
+			formats[i] = templateDateFormat
+		}
+		if _, ok := column.Max.(time.Time); ok && formats[i] == "" {
+			formats[i] = templateDateFormat
+		}
+	}
+
+	// 首个模板工作表沿用新工作簿默认的工作表
+	if t.sheets == 0 {
+		if defaultSheet := f.file.GetSheetName(0); defaultSheet != sheetName {
+			if err := f.file.SetSheetName(defaultSheet, sheetName); err != nil {
+				return fmt.Errorf("rename default sheet failed: %w", err)
+			}
+		}
+	}
+	t.sheets++
+	if err := f.checkParams(sheetName, titles, keys); err != nil {
+		return err
+	}
+	l, err := f.newLayout(sheetName, titles, keys, formats, nil)
+	if err != nil {
+		return err
+	}
+	if err := f.writeTitles(sheetName, titles, nil); err != nil {
+		return err
+	}
+
+	for i, column := range columns {
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		if l.styles[i] > 0 {
+			if err := f.file.SetColStyle(sheetName, col, l.styles[i]); err != nil {
+				return fmt.Errorf("set column style failed: %w", err)
+			}
+		}
+		if column.Required {
+			if err := t.markRequired(sheetName, col+"1"); err != nil {
+				return err
+			}
+		}
+		requirement, err := t.addValidation(sheetName, col, column)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", column.Title, err)
+		}
+		if err := t.writeHelp(sheetName, column, requirement); err != nil {
+			return err
+		}
+	}
+	return f.applyLayout(sheetName, l)
+}
+
+// markRequired 以红色字体显示必填列标题，并添加批注
+func (t *Template) markRequired(sheetName string, cellAddr string) error {
+	f := t.File
+	style := excelize.Style{}
+	if f.titleStyle != nil {
+		style = *f.titleStyle
+	}
+	font := excelize.Font{}
+	if style.Font != nil {
+		font = *style.Font
+	}
+	font.Color = "#FF0000"
+	style.Font = &font
+	styleId, err := f.file.NewStyle(&style)
+	if err != nil {
+		return fmt.Errorf("create required title style failed: %w", err)
+	}
+	if err := f.file.SetCellStyle(sheetName, cellAddr, cellAddr, styleId); err != nil {
+		return fmt.Errorf("set required title style failed: %w", err)
+	}
+	if err := f.file.AddComment(sheetName, excelize.Comment{
+		Cell:   cellAddr,
+		Author: "System",
+		Text:   "必填",
+	}); err != nil {
+		return fmt.Errorf("failed to add comment: %w", err)
+	}
+	return nil
+}
+
+// addValidation 为列的数据行设置校验规则
+//
+// 参数:
+//   - sheetName: 工作表名称
+//   - col: 列名，如 A
+//   - column: 列定义
+//
+// 返回:
+//   - string: 填写要求，没有校验规则时为空
+//   - error: 校验规则错误或设置失败的错误信息
+func (t *Template) addValidation(sheetName string, col string, column TemplateColumn) (string, error) {
+	dv := excelize.NewDataValidation(true)
+	dv.Sqref = fmt.Sprintf("%s2:%s%d", col, col, excelize.TotalRows)
+	var requirement string
+	switch {
+	case len(column.Options) > 0:
+		if err := t.setDropList(dv, column.Options); err != nil {
+			return "", err
+		}
+		requirement = "从下拉列表中选择：" + strings.Join(column.Options[:min(len(column.Options), templateListPreview)], "、")
+		if len(column.Options) > templateListPreview {
+			requirement += fmt.Sprintf(" 等 %d 项", len(column.Options))
+		}
+		dv.SetError(excelize.DataValidationErrorStyleStop, "输入错误", "请从下拉列表中选择")
+	case column.Min != nil || column.Max != nil:
+		var err error
+		if requirement, err = setRange(dv, column.Min, column.Max); err != nil {
+			return "", err
+		}
+		dv.SetError(excelize.DataValidationErrorStyleStop, "输入错误", truncateRunes("请输入"+requirement, excelize.MaxFieldLength))
+	}
+	if column.Note != "" {
+		dv.SetInput(truncateRunes(column.Title, 32), truncateRunes(column.Note, excelize.MaxFieldLength))
+	}
+	if dv.Type == "" && !dv.ShowInputMessage {
+		return "", nil
+	}
+	if err := t.file.AddDataValidation(sheetName, dv); err != nil {
+		return "", fmt.Errorf("add data validation failed: %w", err)
+	}
+	return requirement, nil
+}
+
+// setDropList 设置下拉列表，较长的选项写入隐藏工作表并引用其单元格
+func (t *Template) setDropList(dv *excelize.DataValidation, options []string) error {
+	inline := utf8.RuneCountInString(strings.Join(options, ",")) <= excelize.MaxFieldLength
+	for _, option := range options {
+		if strings.ContainsAny(option, `,"`) {
+			inline = false
+			break
+		}
+	}
+	if inline {
+		return dv.SetDropList(options)
+	}
+
+	if t.options == 0 {
+		if _, err := t.file.NewSheet(templateOptionsSheet); err != nil {
+			return fmt.Errorf("create options sheet failed: %w", err)
+		}
+		if err := t.file.SetSheetVisible(templateOptionsSheet, false); err != nil {
+			return fmt.Errorf("hide options sheet failed: %w", err)
+		}
+	}
+	t.options++
+	col, _ := excelize.ColumnNumberToName(t.options)
+	values := make([]any, len(options))
+	for i, option := range options {
+		values[i] = option
+	}
+	if err := t.file.SetSheetCol(templateOptionsSheet, col+"1", &values); err != nil {
+		return fmt.Errorf("write options failed: %w", err)
+	}
+	dv.SetSqrefDropList(fmt.Sprintf("'%s'!$%s$1:$%s$%d", templateOptionsSheet, col, col, len(options)))
+	return nil
+}
+
+// setRange 设置数值或日期范围校验
+//
+// 参数:
+//   - dv: 数据校验
+//   - minValue: 最小值，nil 为不限制
+//   - maxValue: 最大值，nil 为不限制
+//
+// 返回:
+//   - string: 填写要求，如 整数，1 ~ 100
+//   - error: 范围类型不支持或不一致的错误信息
+func setRange(dv *excelize.DataValidation, minValue any, maxValue any) (string, error) {
+	typ, kind := excelize.DataValidationTypeWhole, "整数"
+	var formulas, texts [2]any
+	for i, value := range [2]any{minValue, maxValue} {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case int:
+			formulas[i], texts[i] = v, v
+		case float64:
+			formulas[i], texts[i] = v, v
+			typ, kind = excelize.DataValidationTypeDecimal, "数字"
+		case time.Time:
+			formulas[i], texts[i] = fmt.Sprintf("DATE(%d,%d,%d)", v.Year(), v.Month(), v.Day()), v.Format(time.DateOnly)
+			typ, kind = excelize.DataValidationTypeDate, "日期"
+		default:
+			return "", fmt.Errorf("range value must be int, float64 or time.Time, got %T", value)
+		}
+	}
+	_, minDate := minValue.(time.Time)
+	_, maxDate := maxValue.(time.Time)
+	if minValue != nil && maxValue != nil && minDate != maxDate {
+		return "", fmt.Errorf("range min and max must be both dates or both numbers")
+	}
+	// int 与 float64 混用时按小数处理
+	for i := range formulas {
+		if v, ok := formulas[i].(int); ok && typ == excelize.DataValidationTypeDecimal {
+			formulas[i] = float64(v)
+		}
+	}
+
+	var err error
+	var requirement string
+	switch {
+	case minValue != nil && maxValue != nil:
+		err = dv.SetRange(formulas[0], formulas[1], typ, excelize.DataValidationOperatorBetween)
+		requirement = fmt.Sprintf("%s，%v ~ %v", kind, texts[0], texts[1])
+	case minValue != nil:
+		err = dv.SetRange(formulas[0], formulas[0], typ, excelize.DataValidationOperatorGreaterThanOrEqual)
+		dv.Formula2 = ""
+		requirement = fmt.Sprintf("%s，不小于 %v", kind, texts[0])
+	default:
+		err = dv.SetRange(formulas[1], formulas[1], typ, excelize.DataValidationOperatorLessThanOrEqual)
+		dv.Formula2 = ""
+		requirement = fmt.Sprintf("%s，不大于 %v", kind, texts[1])
+	}
+	if err != nil {
+		return "", fmt.Errorf("set range failed: %w", err)
+	}
+	return requirement, nil
+}
+
+// writeHelp 在说明工作表中写入列的填写要求，首次写入时创建说明工作表
+func (t *Template) writeHelp(sheetName string, column TemplateColumn, requirement string) error {
+	f := t.File
+	if t.helpRow == 0 {
+		if _, err := f.file.NewSheet(TemplateHelpSheet); err != nil {
+			return fmt.Errorf("create help sheet failed: %w", err)
+		}
+		for _, line := range t.help {
+			t.helpRow++
+			if err := f.file.SetCellValue(TemplateHelpSheet, fmt.Sprintf("A%d", t.helpRow), line); err != nil {
+				return err
+			}
+		}
+		if len(t.help) > 0 {
+			t.helpRow++
+		}
+		t.helpRow++
+		if err := f.writeTitleRow(TemplateHelpSheet, t.helpRow, []string{"工作表", "列", "必填", "填写要求", "说明"}, nil); err != nil {
+			return err
+		}
+		if err := f.file.SetColWidth(TemplateHelpSheet, "D", "E", 40); err != nil {
+			return fmt.Errorf("set column width failed: %w", err)
+		}
+	}
+
+	required := "否"
+	if column.Required {
+		required = "是"
+	}
+	t.helpRow++
+	return f.file.SetSheetRow(TemplateHelpSheet, fmt.Sprintf("A%d", t.helpRow),
+		&[]any{sheetName, column.Title, required, requirement, column.Note})
+}
+
+// truncateRunes 按字符数截断文本
+func truncateRunes(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	return string([]rune(text)[:n])
+}
@@ -0,0 +1,109 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cooooing/cutil/collections/bitmap"
+	"github.com/xuri/excelize/v2"
+)
+
+type gender uint64
+
+const (
+	male gender = 1 << iota
+	female
+)
+
+func TestTemplate(t *testing.T) {
+	genders := bitmap.NewEnum(map[gender]string{male: "男", female: "女"})
+	cities := make([]string, 100)
+	for i := range cities {
+		cities[i] = fmt.Sprintf("城市%d", i+1)
+	}
+
+	tpl := NewTemplate("template.xlsx", "带 * 的列为必填列")
+	err := tpl.AddSheet("用户", []TemplateColumn{
+		{Title: "姓名", Key: "name", Required: true, Note: "真实姓名"},
+		{Title: "性别", Key: "gender", Options: EnumOptions(genders)},
+		{Title: "城市", Key: "city", Options: cities},
+		{Title: "年龄", Key: "age", Min: 0, Max: 150},
+		{Title: "入职日期", Key: "joined", Min: time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := tpl.GetExcelizeFile().WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if sheets := f.GetSheetList(); strings.Join(sheets, ",") != "用户,填写说明,_options" {
+		t.Fatalf("unexpected sheets %v", sheets)
+	}
+	if visible, _ := f.GetSheetVisible(templateOptionsSheet); visible {
+		t.Error("expected options sheet hidden")
+	}
+	if value, _ := f.GetCellValue("用户", "A1"); value != "*姓名" {
+		t.Errorf("expected required mark, got %q", value)
+	}
+
+	dvs, err := f.GetDataValidations("用户")
+	if err != nil {
+		t.Fatal(err)
+	}
+	formulas := make(map[string]string)
+	for _, dv := range dvs {
+		formulas[dv.Sqref[:1]] = dv.Type + " " + dv.Formula1 + " " + dv.Formula2
+	}
+	expected := map[string]string{
+		"A": "  ",
+		"B": `list "男,女" `,
+		"C": "list '_options'!$A$1:$A$100 ",
+		"D": "whole 0 150",
+		"E": "date DATE(2000,1,1) ",
+	}
+	for col, want := range expected {
+		if got := formulas[col]; got != want {
+			t.Errorf("column %s: expected validation %q, got %q", col, want, got)
+		}
+	}
+
+	if value, _ := f.GetCellValue(TemplateHelpSheet, "D7"); value != "整数，0 ~ 150" {
+		t.Errorf("expected range requirement in help sheet, got %q", value)
+	}
+
+	// 带必填标记的模板可以直接导入
+	_ = f.SetSheetRow("用户", "A2", &[]any{"张三", "男", "城市1", 30})
+	buf, err = f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := OpenReader("template.xlsx", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer imported.Close()
+	result, err := imported.ImportToDataMap("用户", []string{"姓名", "年龄"}, []string{"name", "age"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0]["name"] != "张三" {
+		t.Errorf("unexpected import result %v", result.Rows)
+	}
+}
+
+func TestTemplateRangeMismatch(t *testing.T) {
+	tpl := NewTemplate("template.xlsx")
+	err := tpl.AddSheet("Sheet1", []TemplateColumn{{Title: "日期", Key: "date", Min: 1, Max: time.Now()}})
+	if err == nil {
+		t.Fatal("expected error for mismatched range types")
+	}
+}
@@ -0,0 +1,304 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	base2 "github.com/Cooooing/cutil/base"
+	"github.com/Cooooing/cutil/query/base"
+	"github.com/Cooooing/cutil/query/dml"
+)
+
+var (
+	// ErrAuditNoPrimaryKey 审计的模型没有 corm:"primaryKey" 标记的主键
+	ErrAuditNoPrimaryKey = errors.New("audit model must have primary key")
+	// ErrAuditNotSupported 审计不支持分批执行的构建器（如批量更新）
+	ErrAuditNotSupported = errors.New("audit does not support chunked builders such as bulk update")
+)
+
+const (
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditRecord 审计记录，每条被修改或删除的数据对应一条记录
+type AuditRecord struct {
+	Actor      any                    `json:"actor"`      // 操作人，来自上下文
+	Table      string                 `json:"table"`      // 表名
+	Action     string                 `json:"action"`     // 操作类型 update/delete
+	PrimaryKey map[string]any         `json:"primaryKey"` // 主键列 -> 值
+	Changes    map[string]AuditChange `json:"changes"`    // 变更列 -> 变更前后的值，删除时新值为 nil
+	Time       time.Time              `json:"time"`       // 操作时间
+}
+
+// AuditChange 字段变更
+type AuditChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// AuditSink 审计记录写入接口。conn 为执行变更的事务，写入失败时变更一并回滚
+type AuditSink interface {
+	Write(ctx context.Context, conn base.Queryer, records []*AuditRecord) error
+}
+
+// AuditSinkFunc 函数形式的审计记录写入
+type AuditSinkFunc func(ctx context.Context, conn base.Queryer, records []*AuditRecord) error
+
+func (f AuditSinkFunc) Write(ctx context.Context, conn base.Queryer, records []*AuditRecord) error {
+	return f(ctx, conn, records)
+}
+
+// TableAuditSink 将审计记录写入审计表，表结构需包含列：
+// actor, table_name, action, primary_key, changes, created_at（primary_key 与 changes 为 JSON 字符串）
+type TableAuditSink struct {
+	Table string
+}
+
+// NewTableAuditSink 创建写入审计表的审计记录写入
+func NewTableAuditSink(table string) *TableAuditSink {
+	return &TableAuditSink{Table: table}
+}
+
+func (s *TableAuditSink) Write(_ context.Context, conn base.Queryer, records []*AuditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	columns := []string{"actor", "table_name", "action", "primary_key", "changes", "created_at"}
+	// 按参数上限分批写入
+	size := base.MaxParams / len(columns)
+	for start := 0; start < len(records); start += size {
+		if err := s.write(conn, columns, records[start:min(start+size, len(records))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TableAuditSink) write(conn base.Queryer, columns []string, records []*AuditRecord) error {
+	insert := dml.NewInsert().Into(s.Table).Columns(columns...)
+	for _, record := range records {
+		primaryKey, err := json.Marshal(record.PrimaryKey)
+		if err != nil {
+			return fmt.Errorf("marshal audit primary key failed: %w", err)
+		}
+		changes, err := json.Marshal(record.Changes)
+		if err != nil {
+			return fmt.Errorf("marshal audit changes failed: %w", err)
+		}
+		var actor any
+		if record.Actor != nil {
+			actor = fmt.Sprint(record.Actor)
+		}
+		insert.Values(actor, record.Table, record.Action, string(primaryKey), string(changes), record.Time)
+	}
+	s2, args := insert.Build()
+	_, err := conn.Exec(s2, args...)
+	return err
+}
+
+type actorKey struct{}
+
+// WithActor 将操作人写入上下文，审计记录从上下文中读取操作人
+func WithActor(ctx context.Context, actor any) context.Context {
+	return base2.SetContextValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext 从上下文中读取操作人
+func ActorFromContext(ctx context.Context) (any, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	return base2.GetContextValue[actorKey, any](ctx, actorKey{})
+}
+
+// Audit 开启变更审计：更新与删除前读取并锁定受影响的数据，执行后按字段对比生成审计记录写入 sink。
+// 变更与审计记录在同一事务中提交，未处于事务时自动开启事务。模型必须标记主键。
+// 批量更新等分批执行的构建器不支持审计，执行时返回 ErrAuditNotSupported
+func (e *Executor[T]) Audit(sink AuditSink) *Executor[T] {
+	e.audit = sink
+	return e
+}
+
+// auditExec 在事务中执行带审计的更新或删除
+func (e *Executor[T]) auditExec(target base.TargetBuilder, s string, args []any) (sql.Result, error) {
+	if e.tx != nil {
+		result, err := e.auditExecTx(rebind(e.tx, e.getDialect()), target, s, args)
+		return result, base.TranslateError(err)
+	}
+	var result sql.Result
+	fn := func(tx *sql.Tx) (err error) {
+		result, err = e.auditExecTx(rebind(tx, e.getDialect()), target, s, args)
+		return err
+	}
+	if e.retry != nil {
+		return result, TransactionWithRetry(e.db, e.retry, fn)
+	}
+	return result, Transaction(e.db, fn)
+}
+
+func (e *Executor[T]) auditExecTx(conn base.Queryer, target base.TargetBuilder, s string, args []any) (sql.Result, error) {
+	metas := base.GetFieldMetas(reflect.TypeOf((*T)(nil)).Elem())
+	var pks []base.FieldMeta
+	for _, meta := range metas {
+		if meta.IsPrimary {
+			pks = append(pks, meta)
+		}
+	}
+	if len(pks) == 0 {
+		return nil, ErrAuditNoPrimaryKey
+	}
+
+	action := AuditActionUpdate
+	if _, ok := e.builder.(base.DeleteBuilder); ok {
+		action = AuditActionDelete
+	}
+	table, alias, whereSQL, whereArgs := target.Target()
+
+	// 变更前数据，加锁避免读取与变更之间被其他事务修改
+	before := fmt.Sprintf("SELECT * FROM %s", table)
+	if alias != "" {
+		before += " AS " + alias
+	}
+	if whereSQL != "" {
+		before += " WHERE " + whereSQL
+	}
+	before += " FOR UPDATE"
+	e.log(before, whereArgs...)
+	olds, err := base.Raws2Struct[T](conn, before, whereArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	e.log(s, args...)
+	result, err := conn.Exec(s, args...)
+	if err != nil || len(olds) == 0 {
+		return result, err
+	}
+
+	now := time.Now()
+	actor, _ := ActorFromContext(e.ctx)
+	records := make([]*AuditRecord, 0, len(olds))
+	if action == AuditActionDelete {
+		for _, old := range olds {
+			changes := make(map[string]AuditChange, len(metas))
+			ov := reflect.ValueOf(old).Elem()
+			for _, meta := range metas {
+				if value := fieldValue(ov.Field(meta.Index)); value != nil {
+					changes[meta.Column] = AuditChange{Old: value}
+				}
+			}
+			records = append(records, &AuditRecord{Actor: actor, Table: table, Action: action, PrimaryKey: primaryKey(ov, pks), Changes: changes, Time: now})
+		}
+	} else {
+		// 按主键读取变更后数据，包含触发器、默认值等数据库侧的修改
+		newMap := make(map[string]reflect.Value, len(olds))
+		for _, after := range afterQueries(table, pks, olds) {
+			e.log(after.Sql, after.Args...)
+			news, err := base.Raws2Struct[T](conn, after.Sql, after.Args...)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range news {
+				v := reflect.ValueOf(item).Elem()
+				newMap[primaryKeyString(v, pks)] = v
+			}
+		}
+		for _, old := range olds {
+			ov := reflect.ValueOf(old).Elem()
+			nv, ok := newMap[primaryKeyString(ov, pks)]
+			if !ok {
+				// 主键被修改，无法对应变更后的数据
+				continue
+			}
+			changes := diffFields(ov, nv, metas)
+			if len(changes) == 0 {
+				continue
+			}
+			records = append(records, &AuditRecord{Actor: actor, Table: table, Action: action, PrimaryKey: primaryKey(ov, pks), Changes: changes, Time: now})
+		}
+	}
+	if len(records) == 0 {
+		return result, nil
+	}
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := e.audit.Write(ctx, conn, records); err != nil {
+		return nil, fmt.Errorf("write audit records failed: %w", err)
+	}
+	return result, nil
+}
+
+// afterQueries 构建按主键查询变更后数据的sql，按参数上限 base.MaxParams 分批。
+// 单列主键使用 pk IN (...)，复合主键使用 (pk1 = ? AND pk2 = ?) OR ... 组合
+func afterQueries[T any](table string, pks []base.FieldMeta, olds []*T) []base.Statement {
+	size := base.MaxParams / len(pks)
+	statements := make([]base.Statement, 0, (len(olds)+size-1)/size)
+	for start := 0; start < len(olds); start += size {
+		batch := olds[start:min(start+size, len(olds))]
+		args := make([]any, 0, len(batch)*len(pks))
+		conds := make([]string, len(batch))
+		for i, old := range batch {
+			v := reflect.ValueOf(old).Elem()
+			parts := make([]string, len(pks))
+			for j, pk := range pks {
+				parts[j] = pk.Column + " = ?"
+				args = append(args, fieldValue(v.Field(pk.Index)))
+			}
+			conds[i] = "(" + strings.Join(parts, " AND ") + ")"
+		}
+		where := strings.Join(conds, " OR ")
+		if len(pks) == 1 {
+			where = fmt.Sprintf("%s IN (%s)", pks[0].Column, strings.Repeat("?, ", len(batch)-1)+"?")
+		}
+		statements = append(statements, base.Statement{Sql: fmt.Sprintf("SELECT * FROM %s WHERE %s", table, where), Args: args})
+	}
+	return statements
+}
+
+// diffFields 按字段元信息对比变更前后的数据
+func diffFields(before, after reflect.Value, metas []base.FieldMeta) map[string]AuditChange {
+	changes := make(map[string]AuditChange)
+	for _, meta := range metas {
+		ov, nv := fieldValue(before.Field(meta.Index)), fieldValue(after.Field(meta.Index))
+		if !reflect.DeepEqual(ov, nv) {
+			changes[meta.Column] = AuditChange{Old: ov, New: nv}
+		}
+	}
+	return changes
+}
+
+func primaryKey(v reflect.Value, pks []base.FieldMeta) map[string]any {
+	keys := make(map[string]any, len(pks))
+	for _, pk := range pks {
+		keys[pk.Column] = fieldValue(v.Field(pk.Index))
+	}
+	return keys
+}
+
+func primaryKeyString(v reflect.Value, pks []base.FieldMeta) string {
+	parts := make([]string, len(pks))
+	for i, pk := range pks {
+		parts[i] = fmt.Sprint(fieldValue(v.Field(pk.Index)))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// fieldValue 返回字段的值，指针字段解引用，空指针返回 nil
+func fieldValue(field reflect.Value) any {
+	for field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	return field.Interface()
+}
@@ -7,6 +7,60 @@ var (
 	ErrorExecutorNotSupportUpdate = errors.New("this executor not support update")
 	ErrorExecutorNotSupportDelete = errors.New("this executor not support delete")
 	ErrorExecutorNotSupportInsert = errors.New("this executor not support insert")
+	ErrorExecutorLockNeedTx       = errors.New("this executor lock query need transaction")
+	ErrorBuilderNotSupportScope   = errors.New("this builder not support scope")
+	ErrorScopeColumnConflict      = errors.New("statement cannot override scope column")
 )
 
-const ()
+// Dialect 数据库方言
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+)
+
+// LockMode 行锁模式
+type LockMode int
+
+const (
+	LockNone      LockMode = iota // 不加锁
+	LockForUpdate                 // 排他锁 FOR UPDATE
+	LockForShare                  // 共享锁 FOR SHARE
+)
+
+// MaxParams 单条语句的参数上限（MySQL 与 PostgreSQL 均为 65535）
+const MaxParams = 65535
+
+// MatchMode 全文检索模式
+type MatchMode int
+
+const (
+	MatchNatural MatchMode = iota // 自然语言检索
+	MatchBoolean                  // 布尔检索，支持 + - * 等操作符（PostgreSQL 使用 to_tsquery 语法）
+	MatchPhrase                   // 短语检索
+)
+
+var defaultDialect = DialectMySQL
+
+// SetDialect 设置全局默认数据库方言
+func SetDialect(dialect Dialect) {
+	defaultDialect = dialect
+}
+
+// GetDialect 获取全局默认数据库方言
+func GetDialect() Dialect {
+	return defaultDialect
+}
+
+// WithDialect 返回使用 dialect 构建的拷贝，用于外层构建器在构建时将方言传给条件与子查询。
+// dialect 为空或 b 为 nil 时原样返回 b
+func WithDialect[B interface {
+	Clone() B
+	Dialect(dialect Dialect) B
+}](b B, dialect Dialect) B {
+	if dialect == "" || any(b) == nil {
+		return b
+	}
+	return b.Clone().Dialect(dialect)
+}
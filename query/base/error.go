@@ -0,0 +1,139 @@
+package base
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// 数据库错误分类，可通过 errors.Is 判断
+var (
+	ErrNotFound             = errors.New("record not found")
+	ErrDuplicateKey         = errors.New("duplicate key")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrDeadlock             = errors.New("deadlock detected")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrLockTimeout          = errors.New("lock timeout")
+	ErrConnectionLost       = errors.New("connection lost")
+)
+
+var (
+	mysqlDuplicateKeyRegexp = regexp.MustCompile("for key '([^']+)'")
+	mysqlForeignKeyRegexp   = regexp.MustCompile("CONSTRAINT `([^`]+)`")
+)
+
+// DBError 分类后的数据库错误，保留原始驱动错误
+type DBError struct {
+	Kind       error  // 错误分类，如 ErrDuplicateKey
+	Constraint string // 约束名称（可获取时）
+	Err        error  // 原始错误
+}
+
+func (e *DBError) Error() string {
+	if e.Constraint != "" {
+		return e.Kind.Error() + " (" + e.Constraint + "): " + e.Err.Error()
+	}
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *DBError) Is(target error) bool {
+	return e.Kind == target
+}
+
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
+// BuildError 返回构建器记录的构建错误，未实现 ErrBuilder 时返回 nil
+//
+// 参数:
+//   - builder: 构建器
+//
+// 返回:
+//   - error: 构建错误
+func BuildError(builder any) error {
+	if b, ok := builder.(ErrBuilder); ok {
+		return b.Err()
+	}
+	return nil
+}
+
+// TranslateError 将 mysql / postgres 驱动错误转换为分类错误，无法分类时原样返回
+//
+// 参数:
+//   - err: 原始错误
+//
+// 返回:
+//   - error: 分类后的错误
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var dbErr *DBError
+	if errors.As(err, &dbErr) {
+		return err
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &DBError{Kind: ErrNotFound, Err: err}
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, sql.ErrConnDone) {
+		return &DBError{Kind: ErrConnectionLost, Err: err}
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1062, 1586:
+			return &DBError{Kind: ErrDuplicateKey, Constraint: matchConstraint(mysqlDuplicateKeyRegexp, mysqlErr.Message), Err: err}
+		case 1216, 1217, 1451, 1452:
+			return &DBError{Kind: ErrForeignKeyViolation, Constraint: matchConstraint(mysqlForeignKeyRegexp, mysqlErr.Message), Err: err}
+		case 1213:
+			return &DBError{Kind: ErrDeadlock, Err: err}
+		case 1205:
+			return &DBError{Kind: ErrLockTimeout, Err: err}
+		case 2006, 2013:
+			return &DBError{Kind: ErrConnectionLost, Err: err}
+		}
+		return err
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return &DBError{Kind: ErrDuplicateKey, Constraint: pqErr.Constraint, Err: err}
+		case "23503":
+			return &DBError{Kind: ErrForeignKeyViolation, Constraint: pqErr.Constraint, Err: err}
+		case "40P01":
+			return &DBError{Kind: ErrDeadlock, Err: err}
+		case "40001":
+			return &DBError{Kind: ErrSerializationFailure, Err: err}
+		case "55P03":
+			return &DBError{Kind: ErrLockTimeout, Err: err}
+		}
+		// 08 类错误为连接异常
+		if pqErr.Code.Class() == "08" {
+			return &DBError{Kind: ErrConnectionLost, Err: err}
+		}
+		return err
+	}
+	return err
+}
+
+// IsRetryable 判断错误是否为可重试的瞬时错误（死锁、序列化失败）
+func IsRetryable(err error) bool {
+	err = TranslateError(err)
+	return errors.Is(err, ErrDeadlock) || errors.Is(err, ErrSerializationFailure)
+}
+
+func matchConstraint(re *regexp.Regexp, message string) string {
+	if match := re.FindStringSubmatch(message); len(match) > 1 {
+		return match[1]
+	}
+	return ""
+}
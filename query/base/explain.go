@@ -0,0 +1,226 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QueryPlan 与方言无关的执行计划
+type QueryPlan struct {
+	Nodes         []PlanNode // 计划节点（按计划树深度优先顺序）
+	FullScan      bool       // 是否存在全表扫描
+	EstimatedRows int64      // 估算扫描行数（各节点之和）
+	Indexes       []string   // 使用的索引
+	Raw           string     // 数据库返回的原始计划
+}
+
+// PlanNode 执行计划节点
+type PlanNode struct {
+	Table         string // 表名
+	Access        string // 访问方式，如 MySQL 的 ALL/ref，PostgreSQL 的 Seq Scan/Index Scan
+	Index         string // 使用的索引
+	EstimatedRows int64  // 估算行数
+	ActualRows    int64  // 实际行数（仅 ANALYZE 时有效）
+	FullScan      bool   // 是否全表扫描
+}
+
+// FullScanTables 返回全表扫描的表名
+func (p *QueryPlan) FullScanTables() []string {
+	var tables []string
+	for _, node := range p.Nodes {
+		if node.FullScan {
+			tables = append(tables, node.Table)
+		}
+	}
+	return tables
+}
+
+func (p *QueryPlan) addNode(node PlanNode) {
+	p.Nodes = append(p.Nodes, node)
+	p.EstimatedRows += node.EstimatedRows
+	if node.FullScan {
+		p.FullScan = true
+	}
+	if node.Index != "" {
+		for _, index := range p.Indexes {
+			if index == node.Index {
+				return
+			}
+		}
+		p.Indexes = append(p.Indexes, node.Index)
+	}
+}
+
+// ExplainSql 构建查询计划sql
+//
+// 参数:
+//   - dialect: 数据库方言
+//   - query: 查询语句
+//   - analyze: 是否实际执行查询（EXPLAIN ANALYZE）
+//
+// 返回:
+//   - string: 查询计划sql
+func ExplainSql(dialect Dialect, query string, analyze bool) string {
+	switch dialect {
+	case DialectPostgres:
+		if analyze {
+			return "EXPLAIN (ANALYZE, FORMAT JSON) " + query
+		}
+		return "EXPLAIN (FORMAT JSON) " + query
+	default:
+		// MySQL 的 EXPLAIN ANALYZE 仅支持 TREE 格式
+		if analyze {
+			return "EXPLAIN ANALYZE " + query
+		}
+		return "EXPLAIN FORMAT=JSON " + query
+	}
+}
+
+// ParsePlan 解析数据库返回的查询计划
+//
+// 参数:
+//   - dialect: 数据库方言
+//   - raw: 原始计划
+//   - analyze: 是否为 EXPLAIN ANALYZE 的结果
+//
+// 返回:
+//   - *QueryPlan: 执行计划
+//   - error: 解析失败的错误信息
+func ParsePlan(dialect Dialect, raw string, analyze bool) (*QueryPlan, error) {
+	plan := &QueryPlan{Raw: raw}
+	switch {
+	case dialect == DialectPostgres:
+		var root []map[string]any
+		if err := json.Unmarshal([]byte(raw), &root); err != nil {
+			return nil, fmt.Errorf("parse postgres plan failed: %w", err)
+		}
+		for _, item := range root {
+			if node, ok := item["Plan"].(map[string]any); ok {
+				walkPostgresPlan(plan, node)
+			}
+		}
+	case analyze:
+		parseMySQLTreePlan(plan, raw)
+	default:
+		var root map[string]any
+		if err := json.Unmarshal([]byte(raw), &root); err != nil {
+			return nil, fmt.Errorf("parse mysql plan failed: %w", err)
+		}
+		walkMySQLPlan(plan, root)
+	}
+	return plan, nil
+}
+
+// walkPostgresPlan 遍历 PostgreSQL JSON 计划树
+func walkPostgresPlan(plan *QueryPlan, node map[string]any) {
+	if table, ok := node["Relation Name"].(string); ok {
+		access, _ := node["Node Type"].(string)
+		index, _ := node["Index Name"].(string)
+		plan.addNode(PlanNode{
+			Table:         table,
+			Access:        access,
+			Index:         index,
+			EstimatedRows: toInt64(node["Plan Rows"]),
+			ActualRows:    toInt64(node["Actual Rows"]),
+			FullScan:      access == "Seq Scan",
+		})
+	}
+	if children, ok := node["Plans"].([]any); ok {
+		for _, child := range children {
+			if c, ok := child.(map[string]any); ok {
+				walkPostgresPlan(plan, c)
+			}
+		}
+	}
+}
+
+// walkMySQLPlan 遍历 MySQL JSON 计划，表节点可能嵌套在 nested_loop、ordering_operation 等结构中
+func walkMySQLPlan(plan *QueryPlan, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if table, ok := v["table"].(map[string]any); ok {
+			if name, ok := table["table_name"].(string); ok {
+				access, _ := table["access_type"].(string)
+				index, _ := table["key"].(string)
+				plan.addNode(PlanNode{
+					Table:         name,
+					Access:        access,
+					Index:         index,
+					EstimatedRows: toInt64(table["rows_examined_per_scan"]),
+					FullScan:      access == "ALL",
+				})
+			}
+		}
+		// 按键排序，保证节点顺序稳定
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if key == "table" {
+				// 表节点下可能包含物化子查询
+				if table, ok := v[key].(map[string]any); ok {
+					delete(table, "table_name")
+					walkMySQLPlan(plan, table)
+				}
+				continue
+			}
+			walkMySQLPlan(plan, v[key])
+		}
+	case []any:
+		for _, child := range v {
+			walkMySQLPlan(plan, child)
+		}
+	}
+}
+
+var (
+	mysqlTreeTableRegexp = regexp.MustCompile(`-> (Table scan|Index scan|Index lookup|Index range scan|Single-row index lookup|Covering index lookup|Covering index scan) on (\S+)(?: using (\S+))?`)
+	mysqlTreeRowsRegexp  = regexp.MustCompile(`rows=([\d.]+)`)
+)
+
+// parseMySQLTreePlan 解析 MySQL EXPLAIN ANALYZE 的 TREE 格式计划
+func parseMySQLTreePlan(plan *QueryPlan, raw string) {
+	for _, line := range strings.Split(raw, "\n") {
+		match := mysqlTreeTableRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		node := PlanNode{
+			Table:    match[2],
+			Access:   match[1],
+			Index:    match[3],
+			FullScan: match[1] == "Table scan",
+		}
+		// 第一个 rows 为估算行数，第二个为实际行数
+		rows := mysqlTreeRowsRegexp.FindAllStringSubmatch(line, 2)
+		if len(rows) > 0 {
+			node.EstimatedRows = toInt64(rows[0][1])
+		}
+		if len(rows) > 1 {
+			node.ActualRows = toInt64(rows[1][1])
+		}
+		plan.addNode(node)
+	}
+}
+
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case float64:
+		return int64(n)
+	case int64:
+		return n
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0
+		}
+		return int64(f)
+	}
+	return 0
+}
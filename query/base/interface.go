@@ -1,5 +1,10 @@
 package base
 
+import (
+	"context"
+	"database/sql"
+)
+
 // PageRespInterface 分页查询参数接口
 type PageRespInterface[T any] interface {
 	SetList(data []*T)
@@ -11,6 +16,11 @@ type PageRespInterface[T any] interface {
 	GetSize() int
 }
 
+// PageRespCappedInterface 分页查询返回值可选接口，用于标记总数是否达到计数上限
+type PageRespCappedInterface interface {
+	SetTotalCapped(capped bool)
+}
+
 // PageReqInterface 分页查询返回值接口
 type PageReqInterface interface {
 	Validate() error
@@ -18,16 +28,63 @@ type PageReqInterface interface {
 	GetSize() int
 }
 
-// Builder 通用方法接口
+// Queryer 数据库查询接口，*sql.DB 与 *sql.Tx 均已实现
+type Queryer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Builder 通用方法接口。Build 不修改构建器，构建完成的构建器可作为模板并发复用，修改前需 Clone
 type Builder interface {
 	GetSql() string
 	GetArgs() []any
 	Build() (string, []any)
 }
 
+// ScopeBuilder 支持追加范围限定的构建器（如租户隔离）。
+// 查询、更新、删除追加 column = value 条件，插入填充 column 列；同一列重复设置时覆盖
+type ScopeBuilder interface {
+	Scope(column string, value any)
+}
+
+// ErrBuilder 可记录构建错误的构建器（如命名参数绑定失败），执行器执行前检查并返回该错误
+type ErrBuilder interface {
+	Err() error
+}
+
+// TargetBuilder 可获取目标表与过滤条件（含范围限定）的写操作构建器，用于审计读取变更前数据
+type TargetBuilder interface {
+	Target() (table string, alias string, whereSQL string, whereArgs []any)
+}
+
+// Statement 单条sql及其参数
+type Statement struct {
+	Sql  string
+	Args []any
+}
+
+// ChunkedBuilder 可拆分为多条语句执行的构建器（如按参数上限分批的批量更新）
+type ChunkedBuilder interface {
+	BuildChunks() []Statement
+}
+
+// ShardBuilder 支持分表路由的构建器
+type ShardBuilder interface {
+	// LogicalTable 返回逻辑表名
+	LogicalTable() string
+	// ShardValues 返回过滤条件或插入值中 column 的取值，无法确定时返回 false
+	ShardValues(column string) ([]any, bool)
+	// PhysicalTable 设置构建时使用的物理表名，为空时使用逻辑表名
+	PhysicalTable(table string)
+}
+
 type ConditionBuilder interface {
 	Builder
 
+	Clone() ConditionBuilder
+
 	Where(column string, args ...any) ConditionBuilder
 	WhereIf(condition bool, column string, args ...any) ConditionBuilder
 	WhereAlias(tableAlias string, column string, args ...any) ConditionBuilder
@@ -134,6 +191,12 @@ type ConditionBuilder interface {
 	NotInAlias(column string, alias string, args ...any) ConditionBuilder
 	NotInAliasIf(condition bool, column string, alias string, args ...any) ConditionBuilder
 
+	InSelect(column string, builder SelectBuilder) ConditionBuilder
+	InSelectIf(condition bool, column string, builder SelectBuilder) ConditionBuilder
+
+	NotInSelect(column string, builder SelectBuilder) ConditionBuilder
+	NotInSelectIf(condition bool, column string, builder SelectBuilder) ConditionBuilder
+
 	Nested(cond ConditionBuilder) ConditionBuilder
 	NestedIf(condition bool, cond ConditionBuilder) ConditionBuilder
 
@@ -141,14 +204,35 @@ type ConditionBuilder interface {
 	OnIf(condition bool, columnA string, columnB string) ConditionBuilder
 	OnAlias(columnA string, aliasA string, columnB string, aliasB string) ConditionBuilder
 	OnAliasIf(condition bool, columnA string, aliasA string, columnB string, aliasB string) ConditionBuilder
+
+	JsonEq(column string, path string, value any) ConditionBuilder
+	JsonEqIf(condition bool, column string, path string, value any) ConditionBuilder
+
+	JsonContains(column string, path string, value any) ConditionBuilder
+	JsonContainsIf(condition bool, column string, path string, value any) ConditionBuilder
+
+	JsonHasKey(column string, path string) ConditionBuilder
+	JsonHasKeyIf(condition bool, column string, path string) ConditionBuilder
+
+	Match(columns []string, query string, mode MatchMode) ConditionBuilder
+	MatchIf(condition bool, columns []string, query string, mode MatchMode) ConditionBuilder
+
+	Dialect(dialect Dialect) ConditionBuilder
+
+	// ColumnValues 返回顶层 AND 条件中 column 的 = 或 IN 取值，存在 OR 或未找到时返回 false
+	ColumnValues(column string) ([]any, bool)
 }
 
 type SelectBuilder interface {
 	Builder
 
+	Clone() SelectBuilder
+
 	From(table string) SelectBuilder
 	FromAlias(table string, alias string) SelectBuilder
+	FromSelect(builder SelectBuilder, alias string) SelectBuilder
 	Columns(columns ...string) SelectBuilder
+	ColumnSelect(builder SelectBuilder, alias string) SelectBuilder
 
 	InnerJoin(table string, alias string, on ConditionBuilder) SelectBuilder
 	InnerJoinSelect(builder SelectBuilder, alias string, on ConditionBuilder) SelectBuilder
@@ -171,30 +255,75 @@ type SelectBuilder interface {
 
 	Limit(limit int) SelectBuilder
 	Offset(offset int) SelectBuilder
+
+	// BuildCount 构建计数查询，limit 大于 0 时最多统计 limit 行
+	BuildCount(limit int) (string, []any)
+	// BuildExists 构建存在性查询 SELECT 1 ... LIMIT 1
+	BuildExists() (string, []any)
+
+	// Dialect 指定数据库方言，未指定时使用全局默认方言，构建时同样应用于条件与子查询
+	Dialect(dialect Dialect) SelectBuilder
+	ForUpdate() SelectBuilder
+	ForShare() SelectBuilder
+	SkipLocked() SelectBuilder
+	NoWait() SelectBuilder
+	GetLock() LockMode
+
+	GetOrderBy() []string
+	GetLimit() int
+	GetOffset() int
 }
 
 type UpdateBuilder interface {
 	Builder
 
+	Clone() UpdateBuilder
+
 	Table(table string) UpdateBuilder
 	TableAlias(table string, alias string) UpdateBuilder
 	Set(column string, value any) UpdateBuilder
 	Where(cond ConditionBuilder) UpdateBuilder
+	// Dialect 指定数据库方言，构建时应用于过滤条件
+	Dialect(dialect Dialect) UpdateBuilder
 }
 
 type InsertBuilder interface {
 	Builder
 
+	Clone() InsertBuilder
+
 	Into(table string) InsertBuilder
 	Columns(cols ...string) InsertBuilder
 	Values(values ...any) InsertBuilder
 	Select(builder SelectBuilder) InsertBuilder
+	// Dialect 指定数据库方言，构建时应用于来源查询
+	Dialect(dialect Dialect) InsertBuilder
+}
+
+// BulkUpdateBuilder 按主键批量更新，Build 生成包含全部数据的单条语句，BuildChunks 按参数上限分批生成
+type BulkUpdateBuilder interface {
+	Builder
+	ChunkedBuilder
+
+	Clone() BulkUpdateBuilder
+
+	Table(table string) BulkUpdateBuilder
+	Key(column string) BulkUpdateBuilder
+	Columns(cols ...string) BulkUpdateBuilder
+	Rows(rows any) BulkUpdateBuilder
+	Cast(column string, sqlType string) BulkUpdateBuilder
+	ChunkSize(size int) BulkUpdateBuilder
+	Dialect(dialect Dialect) BulkUpdateBuilder
 }
 
 type DeleteBuilder interface {
 	Builder
 
+	Clone() DeleteBuilder
+
 	From(table string) DeleteBuilder
 	FromAlias(table string, alias string) DeleteBuilder
 	Where(cond ConditionBuilder) DeleteBuilder
+	// Dialect 指定数据库方言，构建时应用于过滤条件
+	Dialect(dialect Dialect) DeleteBuilder
 }
@@ -20,12 +20,14 @@ const (
 	FieldTagColumn     = "column"
 	FieldTagComment    = "comment"
 	FieldTagPrimaryKey = "primaryKey"
+	FieldTagTenant     = "tenant"
 )
 
 type FieldMeta struct {
 	Field     reflect.StructField
 	Column    string
 	IsPrimary bool
+	IsTenant  bool // 租户列
 	Comment   string
 	Index     int // 在 struct 中的索引
 }
@@ -53,6 +55,17 @@ func getFieldMetas(t reflect.Type) []FieldMeta {
 	return metas
 }
 
+// GetFieldMetas 获取结构体字段元信息，非结构体类型返回 nil
+func GetFieldMetas(t reflect.Type) []FieldMeta {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return getFieldMetas(t)
+}
+
 // parseCormTag 解析 corm:"..." 标签
 func parseCormTag(sf reflect.StructField) FieldMeta {
 	tag := sf.Tag.Get(FieldTag)
@@ -75,8 +88,10 @@ func parseCormTag(sf reflect.StructField) FieldMeta {
 
 		if len(kv) == 1 {
 			switch strings.ToLower(key) {
-			case FieldTagPrimaryKey:
+			case strings.ToLower(FieldTagPrimaryKey):
 				meta.IsPrimary = true
+			case FieldTagTenant:
+				meta.IsTenant = true
 			}
 			continue
 		}
@@ -269,7 +284,7 @@ func parseTime(s string) (time.Time, error) {
 
 // -----
 
-func Raw2StructByPage[T any](db *sql.DB, page PageReqInterface, query string, args ...any) ([]*T, error) {
+func Raw2StructByPage[T any](db Queryer, page PageReqInterface, query string, args ...any) ([]*T, error) {
 	list, err := Raw2MapByPage(db, page, query, args...)
 	if err != nil {
 		return nil, err
@@ -286,7 +301,7 @@ func Raw2StructByPage[T any](db *sql.DB, page PageReqInterface, query string, ar
 	return result, err
 }
 
-func Raw2MapByPage(db *sql.DB, page PageReqInterface, query string, args ...any) ([]*map[string]any, error) {
+func Raw2MapByPage(db Queryer, page PageReqInterface, query string, args ...any) ([]*map[string]any, error) {
 	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
@@ -351,7 +366,7 @@ func Raw2MapByPage(db *sql.DB, page PageReqInterface, query string, args ...any)
 	return list, nil
 }
 
-func Raws2Struct[T any](db *sql.DB, query string, args ...any) ([]*T, error) {
+func Raws2Struct[T any](db Queryer, query string, args ...any) ([]*T, error) {
 	var (
 		err  error
 		list []*T
@@ -392,7 +407,7 @@ func Raws2Struct[T any](db *sql.DB, query string, args ...any) ([]*T, error) {
 	// return result, err
 }
 
-func Raw2Map(db *sql.DB, query string, args ...any) ([]*map[string]any, error) {
+func Raw2Map(db Queryer, query string, args ...any) ([]*map[string]any, error) {
 	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,20 @@
+package base
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCormTagPrimaryKey(t *testing.T) {
+	type Model struct {
+		Id   int64  `corm:"column:id;primaryKey"`
+		Code string `corm:"column:code;primarykey"`
+		Name string `corm:"column:name"`
+	}
+	want := map[string]bool{"id": true, "code": true, "name": false}
+	for _, meta := range getFieldMetas(reflect.TypeOf(Model{})) {
+		if meta.IsPrimary != want[meta.Column] {
+			t.Errorf("column %s: IsPrimary = %v, want %v", meta.Column, meta.IsPrimary, want[meta.Column])
+		}
+	}
+}
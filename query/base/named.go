@@ -0,0 +1,239 @@
+package base
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BindNamed 将 :name / @name 命名参数改写为方言对应的位置参数（MySQL 为 ?，PostgreSQL 为 $n）。
+// 参数值为切片时自动展开，适用于 IN (:ids)；字符串常量、:: 类型转换与 @@ 系统变量不会被识别为参数
+//
+// 参数:
+//   - dialect: 数据库方言
+//   - query: 包含命名参数的sql
+//   - param: 参数来源，map[string]any 或结构体（按 corm column、json 标签及字段名匹配）
+//
+// 返回:
+//   - string: 改写后的sql
+//   - []any: 位置参数
+//   - error: 参数缺失或类型不支持的错误信息
+func BindNamed(dialect Dialect, query string, param any) (string, []any, error) {
+	return bindNamed(query, param, dialect == DialectPostgres)
+}
+
+// BindNamedPositional 将 :name / @name 命名参数改写为 ? 占位符，供构建器与其他条件的 ? 参数统一编号，
+// 执行时由执行器按方言改写（见 Rebind）
+//
+// 参数:
+//   - query: 包含命名参数的sql
+//   - param: 参数来源，map[string]any 或结构体
+//
+// 返回:
+//   - string: 改写后的sql
+//   - []any: 位置参数
+//   - error: 参数缺失或类型不支持的错误信息
+func BindNamedPositional(query string, param any) (string, []any, error) {
+	return bindNamed(query, param, false)
+}
+
+// IsNamedSource 判断参数是否可作为命名参数来源：map[string]any 或结构体（及其指针），
+// 实现 driver.Valuer 的类型与 time.Time 视为普通参数值
+func IsNamedSource(param any) bool {
+	if _, ok := param.(map[string]any); ok {
+		return true
+	}
+	if _, ok := param.(driver.Valuer); ok || param == nil {
+		return false
+	}
+	rt := reflect.TypeOf(param)
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt.Kind() == reflect.Struct && rt != reflect.TypeOf(time.Time{}) && !reflect.PointerTo(rt).Implements(valuerType)
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+func bindNamed(query string, param any, numbered bool) (string, []any, error) {
+	values, err := namedValues(param)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	placeholder := func() string {
+		if numbered {
+			return "$" + strconv.Itoa(len(args))
+		}
+		return "?"
+	}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			// 跳过字符串常量及引用标识符
+			end := skipQuoted(runes, i)
+			sb.WriteString(string(runes[i:end]))
+			i = end - 1
+		case (r == ':' || r == '@') && i+1 < len(runes) && runes[i+1] == r:
+			// :: 类型转换、@@ 系统变量
+			sb.WriteRune(r)
+			sb.WriteRune(r)
+			i++
+		case (r == ':' || r == '@') && i+1 < len(runes) && isNameStart(runes[i+1]):
+			end := i + 1
+			for end < len(runes) && isNamePart(runes[end]) {
+				end++
+			}
+			name := string(runes[i+1 : end])
+			value, ok := values[name]
+			if !ok {
+				return "", nil, fmt.Errorf("named parameter %s not found", name)
+			}
+			rv := reflect.ValueOf(value)
+			if value != nil && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8 {
+				// 空切片展开为 NULL，避免生成非法的 IN ()
+				if rv.Len() == 0 {
+					sb.WriteString("NULL")
+				}
+				for j := 0; j < rv.Len(); j++ {
+					if j > 0 {
+						sb.WriteString(", ")
+					}
+					args = append(args, rv.Index(j).Interface())
+					sb.WriteString(placeholder())
+				}
+			} else {
+				args = append(args, value)
+				sb.WriteString(placeholder())
+			}
+			i = end - 1
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String(), args, nil
+}
+
+// Rebind 将 ? 占位符改写为方言对应的位置参数，PostgreSQL 改写为 $n，其他方言原样返回。
+// 字符串常量及引用标识符中的 ? 不会被改写，PostgreSQL 的 jsonb ? 运算符需改用 jsonb_exists 等函数
+//
+// 参数:
+//   - dialect: 数据库方言
+//   - query: 使用 ? 占位符的sql
+//
+// 返回:
+//   - string: 改写后的sql
+func Rebind(dialect Dialect, query string) string {
+	if dialect != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var (
+		sb strings.Builder
+		n  int
+	)
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '\'', '"', '`':
+			end := skipQuoted(runes, i)
+			sb.WriteString(string(runes[i:end]))
+			i = end - 1
+		case '?':
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// HasNamed 判断sql中是否包含命名参数
+func HasNamed(query string) bool {
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			i = skipQuoted(runes, i) - 1
+		case (r == ':' || r == '@') && i+1 < len(runes) && runes[i+1] == r:
+			i++
+		case (r == ':' || r == '@') && i+1 < len(runes) && isNameStart(runes[i+1]):
+			return true
+		}
+	}
+	return false
+}
+
+// namedValues 将参数来源转换为 名称 -> 值 的映射
+func namedValues(param any) (map[string]any, error) {
+	if m, ok := param.(map[string]any); ok {
+		return m, nil
+	}
+	rv := reflect.ValueOf(param)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("named parameter source is nil")
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("named parameter map key must be string, got %v", rv.Type().Key())
+		}
+		values := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			values[iter.Key().String()] = iter.Value().Interface()
+		}
+		return values, nil
+	case reflect.Struct:
+		values := make(map[string]any)
+		for _, meta := range getFieldMetas(rv.Type()) {
+			value := rv.Field(meta.Index).Interface()
+			values[meta.Field.Name] = value
+			if name, _, _ := strings.Cut(meta.Field.Tag.Get("json"), ","); name != "" && name != "-" {
+				values[name] = value
+			}
+			values[meta.Column] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("named parameter source must be map or struct, got %v", rv.Type())
+	}
+}
+
+// skipQuoted 返回引号内容结束后的位置，连续两个引号视为转义
+func skipQuoted(runes []rune, start int) int {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == quote {
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(runes)
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isNamePart(r rune) bool {
+	return isNameStart(r) || (r >= '0' && r <= '9')
+}
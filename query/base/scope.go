@@ -0,0 +1,91 @@
+package base
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scopes 构建器的范围限定（如租户隔离），按列保存
+type Scopes []scopeNode
+
+type scopeNode struct {
+	column string
+	value  any
+}
+
+// Set 设置范围限定，同一列重复设置时覆盖
+func (s *Scopes) Set(column string, value any) {
+	for i := range *s {
+		if (*s)[i].column == column {
+			(*s)[i].value = value
+			return
+		}
+	}
+	*s = append(*s, scopeNode{column: column, value: value})
+}
+
+// Each 遍历范围限定
+func (s Scopes) Each(fn func(column string, value any)) {
+	for _, node := range s {
+		fn(node.column, node.value)
+	}
+}
+
+// Has 判断列是否为范围限定列
+func (s Scopes) Has(column string) bool {
+	for _, node := range s {
+		if node.column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Conflict 检查写入列是否包含范围限定列，带表名或别名前缀的列同样视为冲突
+//
+// 参数:
+//   - columns: 语句写入的列
+//
+// 返回:
+//   - error: 包含范围限定列时返回 ErrorScopeColumnConflict，否则返回 nil
+func (s Scopes) Conflict(columns []string) error {
+	for _, node := range s {
+		for _, column := range columns {
+			if column == node.column || strings.HasSuffix(column, "."+node.column) {
+				return fmt.Errorf("%w: %s", ErrorScopeColumnConflict, column)
+			}
+		}
+	}
+	return nil
+}
+
+// Merge 将范围限定合并到 where 条件，原条件整体加括号以避免 OR 优先级问题
+//
+// 参数:
+//   - whereSQL: 原 where 条件
+//   - whereArgs: 原 where 参数
+//   - qualifier: 列限定名（表名或别名），为空时不加前缀
+//
+// 返回:
+//   - string: 合并后的条件
+//   - []any: 合并后的参数
+func (s Scopes) Merge(whereSQL string, whereArgs []any, qualifier string) (string, []any) {
+	if len(s) == 0 {
+		return whereSQL, whereArgs
+	}
+	var parts []string
+	args := make([]any, 0, len(whereArgs)+len(s))
+	if whereSQL != "" {
+		parts = append(parts, fmt.Sprintf("(%s)", whereSQL))
+		args = append(args, whereArgs...)
+	}
+	for _, node := range s {
+		column := node.column
+		if qualifier != "" {
+			column = qualifier + "." + column
+		}
+		parts = append(parts, fmt.Sprintf("%s = ?", column))
+		args = append(args, node.value)
+	}
+	return strings.Join(parts, " AND "), args
+}
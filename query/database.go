@@ -1,11 +1,298 @@
 package sql
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/Cooooing/cutil/base/logger"
+	"github.com/Cooooing/cutil/query/base"
+	"github.com/go-sql-driver/mysql"
+)
+
+// DB 数据库连接管理，在 *sql.DB 的基础上提供连接池配置、启动检测、定期健康检查与等待告警
 type DB struct {
 	*sql.DB
+	config Config
+
+	mu        sync.RWMutex
+	healthy   bool
+	lastCheck time.Time
+	lastErr   error
+	prevStats sql.DBStats
+
+	stop chan struct{}
+	once sync.Once
 }
 
+// Config 连接配置。Debug 与 Dialect 只作用于通过 WithDBExecutor 创建的执行器，不修改全局设置，多个 DB 互不影响
 type Config struct {
-	Debug bool
+	Debug bool // 输出该连接执行的sql
+
+	Dialect base.Dialect // 数据库方言，未指定时使用全局默认方言
+	Driver  string       // 驱动名，默认 MySQL 为 mysql，PostgreSQL 为 postgres
+	DSN     string       // 连接串，可使用 DSNConfig 构建
+
+	MaxOpenConns    int           // 最大打开连接数，0 为不限制
+	MaxIdleConns    int           // 最大空闲连接数，0 使用 database/sql 默认值
+	ConnMaxLifetime time.Duration // 连接最长存活时间，0 为不限制
+	ConnMaxIdleTime time.Duration // 连接最长空闲时间，0 为不限制
+
+	PingRetry    int           // 启动检测失败的重试次数
+	PingInterval time.Duration // 启动检测重试间隔，默认 1s
+	PingTimeout  time.Duration // 单次检测超时，默认 5s
+
+	HealthCheckInterval time.Duration     // 健康检查间隔，0 为不检查
+	WaitAlertThreshold  time.Duration     // 健康检查周期内获取连接的平均等待时间超过该值时告警，0 为不告警
+	OnAlert             func(alert Alert) // 告警回调，默认输出警告日志
+}
+
+// Alert 连接告警
+type Alert struct {
+	Unhealthy bool          // 健康检查失败
+	Err       error         // 健康检查错误
+	AvgWait   time.Duration // 周期内获取连接的平均等待时间
+	Stats     Stats         // 告警时的连接状态
+}
+
+// Stats 连接状态快照
+type Stats struct {
+	sql.DBStats
+	Healthy   bool      // 最近一次检测是否成功
+	LastCheck time.Time // 最近一次检测时间
+	LastError error     // 最近一次检测错误
+}
+
+// DSNConfig 连接串参数
+type DSNConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+	Params   map[string]string // 额外参数，如 MySQL 的 charset、PostgreSQL 的 sslmode
+}
+
+// MySQL 构建 MySQL 连接串，默认 parseTime=true、loc=Local
+func (c DSNConfig) MySQL() string {
+	cfg := mysql.NewConfig()
+	cfg.User = c.User
+	cfg.Passwd = c.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(c.Host, strconv.Itoa(defaultPort(c.Port, 3306)))
+	cfg.DBName = c.Database
+	cfg.ParseTime = true
+	cfg.Loc = time.Local
+	if len(c.Params) > 0 {
+		cfg.Params = make(map[string]string, len(c.Params))
+		for k, v := range c.Params {
+			cfg.Params[k] = v
+		}
+	}
+	return cfg.FormatDSN()
+}
+
+// Postgres 构建 PostgreSQL 连接串（URL 形式），未指定 sslmode 时为 disable
+func (c DSNConfig) Postgres() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   net.JoinHostPort(c.Host, strconv.Itoa(defaultPort(c.Port, 5432))),
+		Path:   "/" + c.Database,
+	}
+	query := url.Values{}
+	for k, v := range c.Params {
+		query.Set(k, v)
+	}
+	if query.Get("sslmode") == "" {
+		query.Set("sslmode", "disable")
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func defaultPort(port int, def int) int {
+	if port > 0 {
+		return port
+	}
+	return def
+}
+
+// Open 按配置打开数据库连接，启动时检测连接可用（失败按 PingRetry 重试），并按需启动健康检查
+//
+// 参数:
+//   - config: 连接配置
+//
+// 返回:
+//   - *DB: 数据库连接
+//   - error: 打开或检测失败的错误信息
+func Open(config Config) (*DB, error) {
+	if config.Driver == "" {
+		config.Driver = string(base.DialectMySQL)
+		if config.Dialect != "" {
+			config.Driver = string(config.Dialect)
+		}
+	}
+	sqlDB, err := sql.Open(config.Driver, config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("open database failed: %w", err)
+	}
+	db := NewDB(sqlDB, config)
+	if err := db.pingWithRetry(); err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+	db.startHealthCheck()
+	return db, nil
+}
+
+// NewDB 使用已打开的连接创建 DB 并应用连接池配置，不进行检测与健康检查
+func NewDB(sqlDB *sql.DB, config Config) *DB {
+	if config.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns != 0 {
+		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+	if config.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	}
+	return &DB{DB: sqlDB, config: config, stop: make(chan struct{})}
+}
+
+// Dialect 返回连接的数据库方言，未配置时为全局默认方言
+func (db *DB) Dialect() base.Dialect {
+	if db.config.Dialect != "" {
+		return db.config.Dialect
+	}
+	return base.GetDialect()
+}
+
+// WithDBExecutor 创建使用 db 执行的执行器，执行器使用连接配置的方言（构建语句与改写占位符）与调试选项
+func WithDBExecutor[T any](db *DB, builder base.Builder) *Executor[T] {
+	executor := WithExecutor[T](db.DB, builder).Dialect(db.Dialect())
+	if db.config.Debug {
+		executor.Debug()
+	}
+	return executor
+}
+
+// Ping 检测连接并记录结果
+func (db *DB) Ping() error {
+	timeout := db.config.PingTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := db.DB.PingContext(ctx)
+
+	db.mu.Lock()
+	db.healthy = err == nil
+	db.lastCheck = time.Now()
+	db.lastErr = err
+	db.mu.Unlock()
+	return err
+}
+
+func (db *DB) pingWithRetry() error {
+	interval := db.config.PingInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	var err error
+	for attempt := 0; attempt <= db.config.PingRetry; attempt++ {
+		if attempt > 0 {
+			logger.Warn("ping database failed, retry %d/%d: %v", attempt, db.config.PingRetry, err)
+			time.Sleep(interval)
+		}
+		if err = db.Ping(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("ping database failed: %w", err)
+}
+
+// startHealthCheck 启动定期健康检查
+func (db *DB) startHealthCheck() {
+	if db.config.HealthCheckInterval <= 0 {
+		return
+	}
+	db.prevStats = db.DB.Stats()
+	go func() {
+		ticker := time.NewTicker(db.config.HealthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-db.stop:
+				return
+			case <-ticker.C:
+				db.check()
+			}
+		}
+	}()
+}
+
+// check 执行一次健康检查，检测失败或平均等待时间超过阈值时告警
+func (db *DB) check() {
+	err := db.Ping()
+	current := db.DB.Stats()
+	var avgWait time.Duration
+	if count := current.WaitCount - db.prevStats.WaitCount; count > 0 {
+		avgWait = (current.WaitDuration - db.prevStats.WaitDuration) / time.Duration(count)
+	}
+	db.prevStats = current
+
+	slow := db.config.WaitAlertThreshold > 0 && avgWait > db.config.WaitAlertThreshold
+	if err == nil && !slow {
+		return
+	}
+	alert := Alert{Unhealthy: err != nil, Err: err, AvgWait: avgWait, Stats: db.Stats()}
+	if db.config.OnAlert != nil {
+		db.config.OnAlert(alert)
+		return
+	}
+	if alert.Unhealthy {
+		logger.Warn("database health check failed: %v", err)
+	}
+	if slow {
+		logger.Warn("database connection wait too long: avg %s, in use %d/%d, wait count %d",
+			avgWait, alert.Stats.InUse, alert.Stats.MaxOpenConnections, alert.Stats.WaitCount)
+	}
+}
+
+// Stats 返回连接状态快照，包含 sql.DB.Stats() 与最近一次检测结果。
+// 覆盖内嵌 *sql.DB 的同名方法，仅需连接池统计时可使用 db.DB.Stats()
+func (db *DB) Stats() Stats {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return Stats{
+		DBStats:   db.DB.Stats(),
+		Healthy:   db.healthy,
+		LastCheck: db.lastCheck,
+		LastError: db.lastErr,
+	}
+}
+
+// Healthy 返回最近一次检测是否成功
+func (db *DB) Healthy() bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.healthy
+}
+
+// Close 停止健康检查并关闭连接
+func (db *DB) Close() error {
+	db.once.Do(func() {
+		close(db.stop)
+	})
+	return db.DB.Close()
 }
@@ -0,0 +1,361 @@
+package dml
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Cooooing/cutil/query/base"
+)
+
+type BulkUpdate struct {
+	table     string
+	key       string
+	cols      []string
+	rows      []map[string]any
+	casts     map[string]string
+	chunkSize int
+	dialect   base.Dialect
+	scopes    base.Scopes
+
+	structCols []string // 结构体行的列顺序
+	structKey  string   // 结构体行的主键列
+}
+
+func NewBulkUpdate() base.BulkUpdateBuilder {
+	return &BulkUpdate{}
+}
+
+// Clone 深拷贝批量更新，行数据添加后不再修改，拷贝间共享
+func (b *BulkUpdate) Clone() base.BulkUpdateBuilder {
+	clone := *b
+	clone.cols = append([]string(nil), b.cols...)
+	clone.rows = append([]map[string]any(nil), b.rows...)
+	if b.casts != nil {
+		clone.casts = make(map[string]string, len(b.casts))
+		for k, v := range b.casts {
+			clone.casts[k] = v
+		}
+	}
+	clone.scopes = append(base.Scopes(nil), b.scopes...)
+	clone.structCols = append([]string(nil), b.structCols...)
+	return &clone
+}
+
+// Err 显式指定的更新列包含范围限定列时返回 base.ErrorScopeColumnConflict，避免将行改到其他范围
+func (b *BulkUpdate) Err() error {
+	return b.scopes.Conflict(b.cols)
+}
+
+func (b *BulkUpdate) Table(table string) base.BulkUpdateBuilder {
+	b.table = table
+	return b
+}
+
+// Key 指定主键列，结构体行默认使用 corm:"primaryKey" 标记的列
+func (b *BulkUpdate) Key(column string) base.BulkUpdateBuilder {
+	b.key = column
+	return b
+}
+
+// Columns 指定更新的列，默认为除主键与范围限定列外的全部列（map 行按列名排序）
+func (b *BulkUpdate) Columns(cols ...string) base.BulkUpdateBuilder {
+	b.cols = append(b.cols, cols...)
+	return b
+}
+
+// Rows 添加待更新的数据，支持结构体、map[string]any 及其切片
+func (b *BulkUpdate) Rows(rows any) base.BulkUpdateBuilder {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			b.addRow(rv.Index(i))
+		}
+		return b
+	}
+	b.addRow(rv)
+	return b
+}
+
+func (b *BulkUpdate) addRow(rv reflect.Value) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			panic("bulk update row must not be nil")
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			panic(fmt.Sprintf("bulk update row map key must be string, got %v", rv.Type().Key()))
+		}
+		row := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			row[iter.Key().String()] = iter.Value().Interface()
+		}
+		b.rows = append(b.rows, row)
+	case reflect.Struct:
+		metas := base.GetFieldMetas(rv.Type())
+		row := make(map[string]any, len(metas))
+		var cols []string
+		for _, meta := range metas {
+			row[meta.Column] = rv.Field(meta.Index).Interface()
+			cols = append(cols, meta.Column)
+			if meta.IsPrimary && b.structKey == "" {
+				b.structKey = meta.Column
+			}
+		}
+		if b.structCols == nil {
+			b.structCols = cols
+		}
+		b.rows = append(b.rows, row)
+	default:
+		panic(fmt.Sprintf("bulk update row must be struct or map, got %v", rv.Type()))
+	}
+}
+
+// Cast 指定列的 sql 类型。PostgreSQL 的 VALUES 无法推断参数类型，未指定时按首个非空值的 go 类型推断，
+// 无法推断时（字符串或全部为空）使用目标表中该列的类型
+func (b *BulkUpdate) Cast(column string, sqlType string) base.BulkUpdateBuilder {
+	if b.casts == nil {
+		b.casts = make(map[string]string)
+	}
+	b.casts[column] = sqlType
+	return b
+}
+
+// ChunkSize 指定每条语句最多更新的行数，同时受参数上限 base.MaxParams 限制
+func (b *BulkUpdate) ChunkSize(size int) base.BulkUpdateBuilder {
+	b.chunkSize = size
+	return b
+}
+
+func (b *BulkUpdate) Dialect(dialect base.Dialect) base.BulkUpdateBuilder {
+	b.dialect = dialect
+	return b
+}
+
+// Scope 追加范围限定条件 column = value
+func (b *BulkUpdate) Scope(column string, value any) {
+	b.scopes.Set(column, value)
+}
+
+func (b *BulkUpdate) Build() (string, []any) {
+	key, cols := b.prepare()
+	return b.build(key, cols, b.rows)
+}
+
+// BuildChunks 按行数与参数上限分批构建语句
+func (b *BulkUpdate) BuildChunks() []base.Statement {
+	key, cols := b.prepare()
+
+	// 每行参数：MySQL 为每列 WHEN ? THEN ? 与 IN 中的主键，PostgreSQL 为 VALUES 中的主键与各列
+	perRow := len(cols) + 1
+	if b.getDialect() != base.DialectPostgres {
+		perRow = 2*len(cols) + 1
+	}
+	// 单行参数超过上限时每条语句仍至少包含一行，由数据库报告参数过多
+	size := max((base.MaxParams-len(b.scopes))/perRow, 1)
+	if b.chunkSize > 0 && b.chunkSize < size {
+		size = b.chunkSize
+	}
+
+	var statements []base.Statement
+	for start := 0; start < len(b.rows); start += size {
+		end := min(start+size, len(b.rows))
+		s, args := b.build(key, cols, b.rows[start:end])
+		statements = append(statements, base.Statement{Sql: s, Args: args})
+	}
+	return statements
+}
+
+func (b *BulkUpdate) GetSql() string {
+	sql, _ := b.Build()
+	return sql
+}
+
+func (b *BulkUpdate) GetArgs() []any {
+	_, args := b.Build()
+	return args
+}
+
+func (b *BulkUpdate) getDialect() base.Dialect {
+	if b.dialect != "" {
+		return b.dialect
+	}
+	return base.GetDialect()
+}
+
+// prepare 确定主键与更新列，并校验每行数据完整
+func (b *BulkUpdate) prepare() (string, []string) {
+	if b.table == "" || len(b.rows) == 0 {
+		panic("bulk update must have table and rows")
+	}
+	key := b.keyColumn()
+	if key == "" {
+		panic("bulk update must have key")
+	}
+
+	cols := b.columns(key)
+	if len(cols) == 0 {
+		panic("bulk update must have columns")
+	}
+
+	for _, row := range b.rows {
+		if _, ok := row[key]; !ok {
+			panic(fmt.Sprintf("bulk update row missing key %s", key))
+		}
+		for _, column := range cols {
+			if _, ok := row[column]; !ok {
+				panic(fmt.Sprintf("bulk update row missing column %s", column))
+			}
+		}
+	}
+	return key, cols
+}
+
+func (b *BulkUpdate) build(key string, cols []string, rows []map[string]any) (string, []any) {
+	if b.getDialect() == base.DialectPostgres {
+		return b.buildPostgres(key, cols, rows)
+	}
+	return b.buildMySQL(key, cols, rows)
+}
+
+// buildMySQL UPDATE t SET col = CASE pk WHEN ? THEN ? ... END WHERE pk IN (...)
+func (b *BulkUpdate) buildMySQL(key string, cols []string, rows []map[string]any) (string, []any) {
+	var args []any
+	sets := make([]string, len(cols))
+	for i, column := range cols {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%s = CASE %s", column, key))
+		for _, row := range rows {
+			sb.WriteString(" WHEN ? THEN ?")
+			args = append(args, row[key], row[column])
+		}
+		sb.WriteString(" END")
+		sets[i] = sb.String()
+	}
+
+	placeholders := make([]string, len(rows))
+	var keyArgs []any
+	for i, row := range rows {
+		placeholders[i] = "?"
+		keyArgs = append(keyArgs, row[key])
+	}
+	whereSQL, whereArgs := b.scopes.Merge(fmt.Sprintf("%s IN (%s)", key, strings.Join(placeholders, ", ")), keyArgs, "")
+	args = append(args, whereArgs...)
+
+	return fmt.Sprintf("UPDATE %s SET %s WHERE %s", b.table, strings.Join(sets, ", "), whereSQL), args
+}
+
+// buildPostgres UPDATE t SET col = v.col FROM (VALUES (...), ...) AS v(pk, col) WHERE t.pk = v.pk。
+// 占位符为 ?，执行时由执行器改写为 $n
+func (b *BulkUpdate) buildPostgres(key string, cols []string, rows []map[string]any) (string, []any) {
+	all := append([]string{key}, cols...)
+
+	// 首行参数带类型转换，确定 VALUES 各列类型。未知类型的参数在 VALUES 中会被推断为 text，
+	// 无法由 go 类型推断的列通过 COALESCE 与表的行类型中对应的列 (NULL::t).col 统一为该列的类型
+	types := make([]string, len(all))
+	for i, column := range all {
+		if sqlType, ok := b.casts[column]; ok {
+			types[i] = sqlType
+			continue
+		}
+		for _, row := range rows {
+			if sqlType := postgresType(row[column]); sqlType != "" {
+				types[i] = sqlType
+				break
+			}
+		}
+	}
+
+	var args []any
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		placeholders := make([]string, len(all))
+		for j, column := range all {
+			placeholders[j] = "?"
+			if i == 0 {
+				placeholders[j] = fmt.Sprintf("COALESCE(?, (NULL::%s).%s)", b.table, column)
+				if types[j] != "" {
+					placeholders[j] = fmt.Sprintf("CAST(? AS %s)", types[j])
+				}
+			}
+			args = append(args, row[column])
+		}
+		values[i] = fmt.Sprintf("(%s)", strings.Join(placeholders, ", "))
+	}
+
+	sets := make([]string, len(cols))
+	for i, column := range cols {
+		sets[i] = fmt.Sprintf("%s = v.%s", column, column)
+	}
+	whereSQL, whereArgs := b.scopes.Merge(fmt.Sprintf("%s.%s = v.%s", b.table, key, key), nil, b.table)
+	args = append(args, whereArgs...)
+
+	return fmt.Sprintf("UPDATE %s SET %s FROM (VALUES %s) AS v(%s) WHERE %s",
+		b.table, strings.Join(sets, ", "), strings.Join(values, ", "), strings.Join(all, ", "), whereSQL), args
+}
+
+// postgresType 按 go 类型推断 PostgreSQL 类型，字符串及无法推断的类型返回空（VALUES 默认推断为 text）
+func postgresType(value any) string {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+	if rv.Type() == reflect.TypeOf(time.Time{}) {
+		return "timestamptz"
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "bigint"
+	case reflect.Float32, reflect.Float64:
+		return "double precision"
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return "bytea"
+		}
+	}
+	return ""
+}
+
+// keyColumn 返回主键列，未指定时使用结构体行的主键
+func (b *BulkUpdate) keyColumn() string {
+	if b.key != "" {
+		return b.key
+	}
+	return b.structKey
+}
+
+// columns 返回更新列，未指定时使用结构体行或首行 map 中除主键与范围限定列外的全部列
+func (b *BulkUpdate) columns(key string) []string {
+	if len(b.cols) > 0 {
+		return b.cols
+	}
+	all := b.structCols
+	if all == nil && len(b.rows) > 0 {
+		for column := range b.rows[0] {
+			all = append(all, column)
+		}
+		sort.Strings(all)
+	}
+	var cols []string
+	for _, column := range all {
+		if column != key && !b.scopes.Has(column) {
+			cols = append(cols, column)
+		}
+	}
+	return cols
+}
@@ -11,12 +11,30 @@ type Delete struct {
 	table      string
 	tableAlias string
 	whereCond  base.ConditionBuilder
+	dialect    base.Dialect
+	scopes     base.Scopes
+	physical   string // 分表路由后的物理表名
 }
 
 func NewDelete() *Delete {
 	return &Delete{}
 }
 
+// Clone 深拷贝删除
+func (d *Delete) Clone() base.DeleteBuilder {
+	clone := *d
+	if d.whereCond != nil {
+		clone.whereCond = d.whereCond.Clone()
+	}
+	clone.scopes = append(base.Scopes(nil), d.scopes...)
+	return &clone
+}
+
+// Err 返回过滤条件中记录的构建错误
+func (d *Delete) Err() error {
+	return base.BuildError(d.whereCond)
+}
+
 func (d *Delete) From(table string) base.DeleteBuilder {
 	d.table = table
 	d.tableAlias = ""
@@ -34,28 +52,75 @@ func (d *Delete) Where(cond base.ConditionBuilder) base.DeleteBuilder {
 	return d
 }
 
+func (d *Delete) Dialect(dialect base.Dialect) base.DeleteBuilder {
+	d.dialect = dialect
+	return d
+}
+
+// Scope 追加范围限定条件 column = value
+func (d *Delete) Scope(column string, value any) {
+	d.scopes.Set(column, value)
+}
+
 func (d *Delete) Build() (string, []any) {
 	if d.table == "" {
 		panic("delete must have table")
 	}
 
-	sqlParts := []string{fmt.Sprintf("DELETE FROM %s", d.table)}
+	sqlParts := []string{fmt.Sprintf("DELETE FROM %s", d.tableName())}
 	if d.tableAlias != "" {
 		sqlParts[0] += " AS " + d.tableAlias
 	}
 
 	var args []any
-	if d.whereCond != nil {
-		whereSQL, whereArgs := d.whereCond.Build()
-		if whereSQL != "" {
-			sqlParts = append(sqlParts, "WHERE "+whereSQL)
-			args = append(args, whereArgs...)
-		}
+	whereSQL, whereArgs := d.buildWhere()
+	if whereSQL != "" {
+		sqlParts = append(sqlParts, "WHERE "+whereSQL)
+		args = append(args, whereArgs...)
 	}
 
 	return strings.Join(sqlParts, " "), args
 }
 
+// Target 返回目标表、别名及合并范围限定后的过滤条件
+func (d *Delete) Target() (string, string, string, []any) {
+	whereSQL, whereArgs := d.buildWhere()
+	return d.tableName(), d.tableAlias, whereSQL, whereArgs
+}
+
+// LogicalTable 返回逻辑表名
+func (d *Delete) LogicalTable() string {
+	return d.table
+}
+
+// ShardValues 返回 where 条件中 column 的取值
+func (d *Delete) ShardValues(column string) ([]any, bool) {
+	if d.whereCond == nil {
+		return nil, false
+	}
+	return d.whereCond.ColumnValues(column)
+}
+
+func (d *Delete) PhysicalTable(table string) {
+	d.physical = table
+}
+
+func (d *Delete) tableName() string {
+	if d.physical != "" {
+		return d.physical
+	}
+	return d.table
+}
+
+func (d *Delete) buildWhere() (string, []any) {
+	var whereSQL string
+	var whereArgs []any
+	if d.whereCond != nil {
+		whereSQL, whereArgs = base.WithDialect(d.whereCond, d.dialect).Build()
+	}
+	return d.scopes.Merge(whereSQL, whereArgs, d.tableAlias)
+}
+
 func (d *Delete) GetSql() string {
 	sql, _ := d.Build()
 	return sql
@@ -8,16 +8,45 @@ import (
 )
 
 type Insert struct {
-	table   string
-	cols    []string
-	values  [][]any
-	selectQ base.SelectBuilder
+	table    string
+	cols     []string
+	values   [][]any
+	selectQ  base.SelectBuilder
+	dialect  base.Dialect
+	scopes   base.Scopes
+	physical string // 分表路由后的物理表名
 }
 
 func NewInsert() base.InsertBuilder {
 	return &Insert{}
 }
 
+// Clone 深拷贝插入
+func (i *Insert) Clone() base.InsertBuilder {
+	clone := *i
+	clone.cols = append([]string(nil), i.cols...)
+	clone.values = make([][]any, len(i.values))
+	for j, row := range i.values {
+		clone.values[j] = append([]any(nil), row...)
+	}
+	if i.selectQ != nil {
+		clone.selectQ = i.selectQ.Clone()
+	}
+	clone.scopes = append(base.Scopes(nil), i.scopes...)
+	return &clone
+}
+
+// Err 返回来源查询中记录的构建错误；INSERT ... SELECT 的插入列包含范围限定列时返回 base.ErrorScopeColumnConflict
+func (i *Insert) Err() error {
+	if i.selectQ == nil {
+		return nil
+	}
+	if err := i.scopes.Conflict(i.cols); err != nil {
+		return err
+	}
+	return base.BuildError(i.selectQ)
+}
+
 func (i *Insert) Into(table string) base.InsertBuilder {
 	i.table = table
 	return i
@@ -41,23 +70,78 @@ func (i *Insert) Select(builder base.SelectBuilder) base.InsertBuilder {
 	return i
 }
 
+func (i *Insert) Dialect(dialect base.Dialect) base.InsertBuilder {
+	i.dialect = dialect
+	return i
+}
+
+// Scope 填充范围限定列 column = value，已存在的列将被覆盖
+func (i *Insert) Scope(column string, value any) {
+	i.scopes.Set(column, value)
+}
+
 func (i *Insert) Build() (string, []any) {
 	if i.table == "" || len(i.cols) == 0 {
 		panic("insert must have table and columns")
 	}
 
-	sqlParts := []string{fmt.Sprintf("INSERT INTO %s (%s)", i.table, strings.Join(i.cols, ", "))}
+	// 合并范围限定列，不修改原始列与值
+	cols := append([]string(nil), i.cols...)
+	var scopeIndexes []int
+	var scopeValues []any
+	i.scopes.Each(func(column string, value any) {
+		idx := -1
+		for j, col := range cols {
+			if col == column {
+				idx = j
+				break
+			}
+		}
+		if idx == -1 {
+			idx = len(cols)
+			cols = append(cols, column)
+		}
+		scopeIndexes = append(scopeIndexes, idx)
+		scopeValues = append(scopeValues, value)
+	})
+
+	table := i.table
+	if i.physical != "" {
+		table = i.physical
+	}
+	sqlParts := []string{fmt.Sprintf("INSERT INTO %s (%s)", table, strings.Join(cols, ", "))}
 	var args []any
 
 	if i.selectQ != nil {
-		sqlParts = append(sqlParts, "("+i.selectQ.GetSql()+")")
-		args = append(args, i.selectQ.GetArgs()...)
+		selectSQL, selectArgs := base.WithDialect(i.selectQ, i.dialect).Build()
+		if len(cols) > len(i.cols) {
+			// 范围限定列以参数形式追加到查询结果
+			placeholders := make([]string, len(cols)-len(i.cols))
+			for j := range placeholders {
+				placeholders[j] = "?"
+			}
+			sqlParts = append(sqlParts, fmt.Sprintf("SELECT t.*, %s FROM (%s) AS t", strings.Join(placeholders, ", "), selectSQL))
+		} else {
+			sqlParts = append(sqlParts, "("+selectSQL+")")
+		}
+		// 查询结果已包含的范围限定列无法覆盖，由 Err 返回错误
+		for j, idx := range scopeIndexes {
+			if idx >= len(i.cols) {
+				args = append(args, scopeValues[j])
+			}
+		}
+		args = append(args, selectArgs...)
 	} else if len(i.values) > 0 {
 		var valPlaceholders []string
-		for _, row := range i.values {
-			if len(row) != len(i.cols) {
+		for _, values := range i.values {
+			if len(values) != len(i.cols) {
 				panic("values count must match columns count")
 			}
+			row := make([]any, len(cols))
+			copy(row, values)
+			for j, idx := range scopeIndexes {
+				row[idx] = scopeValues[j]
+			}
 			placeholders := make([]string, len(row))
 			for j := range row {
 				placeholders[j] = "?"
@@ -73,6 +157,38 @@ func (i *Insert) Build() (string, []any) {
 	return strings.Join(sqlParts, " "), args
 }
 
+// LogicalTable 返回逻辑表名
+func (i *Insert) LogicalTable() string {
+	return i.table
+}
+
+// ShardValues 返回插入值中 column 的取值，每行一个；插入查询结果或存在缺少该列值的行时返回 false
+func (i *Insert) ShardValues(column string) ([]any, bool) {
+	idx := -1
+	for j, col := range i.cols {
+		if col == column {
+			idx = j
+			break
+		}
+	}
+	if idx == -1 || i.selectQ != nil {
+		return nil, false
+	}
+	values := make([]any, len(i.values))
+	for j, row := range i.values {
+		// 值在列追加前添加时，行长度可能小于列数
+		if idx >= len(row) {
+			return nil, false
+		}
+		values[j] = row[idx]
+	}
+	return values, true
+}
+
+func (i *Insert) PhysicalTable(table string) {
+	i.physical = table
+}
+
 func (i *Insert) GetSql() string {
 	sql, _ := i.Build()
 	return sql
@@ -13,12 +13,35 @@ type Update struct {
 	setCols    []string
 	setArgs    []any
 	whereCond  base.ConditionBuilder
+	dialect    base.Dialect
+	scopes     base.Scopes
+	physical   string // 分表路由后的物理表名
 }
 
 func NewUpdate() base.UpdateBuilder {
 	return &Update{}
 }
 
+// Clone 深拷贝更新
+func (u *Update) Clone() base.UpdateBuilder {
+	clone := *u
+	clone.setCols = append([]string(nil), u.setCols...)
+	clone.setArgs = append([]any(nil), u.setArgs...)
+	if u.whereCond != nil {
+		clone.whereCond = u.whereCond.Clone()
+	}
+	clone.scopes = append(base.Scopes(nil), u.scopes...)
+	return &clone
+}
+
+// Err 返回过滤条件中记录的构建错误；SET 包含范围限定列时返回 base.ErrorScopeColumnConflict，避免将行改到其他范围
+func (u *Update) Err() error {
+	if err := u.scopes.Conflict(u.setCols); err != nil {
+		return err
+	}
+	return base.BuildError(u.whereCond)
+}
+
 func (u *Update) Table(table string) base.UpdateBuilder {
 	u.table = table
 	u.tableAlias = ""
@@ -32,7 +55,7 @@ func (u *Update) TableAlias(table, alias string) base.UpdateBuilder {
 }
 
 func (u *Update) Set(column string, value any) base.UpdateBuilder {
-	u.setCols = append(u.setCols, fmt.Sprintf("%s = ?", column))
+	u.setCols = append(u.setCols, column)
 	u.setArgs = append(u.setArgs, value)
 	return u
 }
@@ -42,32 +65,83 @@ func (u *Update) Where(cond base.ConditionBuilder) base.UpdateBuilder {
 	return u
 }
 
+func (u *Update) Dialect(dialect base.Dialect) base.UpdateBuilder {
+	u.dialect = dialect
+	return u
+}
+
+// Scope 追加范围限定条件 column = value
+func (u *Update) Scope(column string, value any) {
+	u.scopes.Set(column, value)
+}
+
 func (u *Update) Build() (string, []any) {
 	if u.table == "" || len(u.setCols) == 0 {
 		panic("update must have table and set columns")
 	}
 
-	sqlParts := []string{fmt.Sprintf("UPDATE %s", u.table)}
+	sqlParts := []string{fmt.Sprintf("UPDATE %s", u.tableName())}
 	if u.tableAlias != "" {
 		sqlParts[0] += " AS " + u.tableAlias
 	}
 
-	sqlParts = append(sqlParts, "SET "+strings.Join(u.setCols, ", "))
+	sets := make([]string, len(u.setCols))
+	for i, column := range u.setCols {
+		sets[i] = fmt.Sprintf("%s = ?", column)
+	}
+	sqlParts = append(sqlParts, "SET "+strings.Join(sets, ", "))
 
 	args := make([]any, len(u.setArgs))
 	copy(args, u.setArgs)
 
-	if u.whereCond != nil {
-		whereSQL, whereArgs := u.whereCond.Build()
-		if whereSQL != "" {
-			sqlParts = append(sqlParts, "WHERE "+whereSQL)
-			args = append(args, whereArgs...)
-		}
+	whereSQL, whereArgs := u.buildWhere()
+	if whereSQL != "" {
+		sqlParts = append(sqlParts, "WHERE "+whereSQL)
+		args = append(args, whereArgs...)
 	}
 
 	return strings.Join(sqlParts, " "), args
 }
 
+// Target 返回目标表、别名及合并范围限定后的过滤条件
+func (u *Update) Target() (string, string, string, []any) {
+	whereSQL, whereArgs := u.buildWhere()
+	return u.tableName(), u.tableAlias, whereSQL, whereArgs
+}
+
+// LogicalTable 返回逻辑表名
+func (u *Update) LogicalTable() string {
+	return u.table
+}
+
+// ShardValues 返回 where 条件中 column 的取值
+func (u *Update) ShardValues(column string) ([]any, bool) {
+	if u.whereCond == nil {
+		return nil, false
+	}
+	return u.whereCond.ColumnValues(column)
+}
+
+func (u *Update) PhysicalTable(table string) {
+	u.physical = table
+}
+
+func (u *Update) tableName() string {
+	if u.physical != "" {
+		return u.physical
+	}
+	return u.table
+}
+
+func (u *Update) buildWhere() (string, []any) {
+	var whereSQL string
+	var whereArgs []any
+	if u.whereCond != nil {
+		whereSQL, whereArgs = base.WithDialect(u.whereCond, u.dialect).Build()
+	}
+	return u.scopes.Merge(whereSQL, whereArgs, u.tableAlias)
+}
+
 func (u *Update) GetSql() string {
 	sql, _ := u.Build()
 	return sql
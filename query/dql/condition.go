@@ -1,6 +1,8 @@
 package dql
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -8,19 +10,46 @@ import (
 )
 
 type conditionNode struct {
-	expr string
-	args []any
-	op   string
+	expr   string
+	args   []any
+	op     string
+	column string // = 与 IN 条件的列名，用于分片路由
+	// render 构建时按条件的方言渲染表达式与参数，用于 JSON、全文检索、嵌套条件与子查询，为 nil 时使用 expr 与 args
+	render func(dialect base.Dialect) (string, []any)
 }
 
 type Condition struct {
-	nodes []conditionNode
+	nodes   []conditionNode
+	dialect base.Dialect
+	err     error // 构建错误，如命名参数绑定失败
 }
 
 func NewCondition() base.ConditionBuilder {
 	return &Condition{}
 }
 
+// Clone 深拷贝条件
+func (c *Condition) Clone() base.ConditionBuilder {
+	nodes := make([]conditionNode, len(c.nodes))
+	for i, node := range c.nodes {
+		node.args = append([]any(nil), node.args...)
+		nodes[i] = node
+	}
+	return &Condition{nodes: nodes, dialect: c.dialect, err: c.err}
+}
+
+// Err 返回构建条件时记录的第一个错误，包括嵌套条件与子查询的错误
+func (c *Condition) Err() error {
+	return c.err
+}
+
+// setErr 记录构建错误，只保留第一个
+func (c *Condition) setErr(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
 func (c *Condition) GetSql() string {
 	sql, _ := c.Build()
 	return sql
@@ -32,6 +61,25 @@ func (c *Condition) GetArgs() []any {
 }
 
 func (c *Condition) append(expr string, args ...any) base.ConditionBuilder {
+	return c.appendNode(conditionNode{expr: expr, args: args})
+}
+
+// appendRender 添加构建时按方言渲染的条件
+func (c *Condition) appendRender(render func(dialect base.Dialect) (string, []any)) base.ConditionBuilder {
+	return c.appendNode(conditionNode{render: render})
+}
+
+// appendSelect 添加子查询条件，子查询在添加时拷贝，构建时使用条件的方言
+func (c *Condition) appendSelect(prefix string, builder base.SelectBuilder, suffix string) base.ConditionBuilder {
+	c.setErr(base.BuildError(builder))
+	sub := builder.Clone()
+	return c.appendRender(func(dialect base.Dialect) (string, []any) {
+		sql, args := base.WithDialect(sub, dialect).Build()
+		return prefix + sql + suffix, args
+	})
+}
+
+func (c *Condition) appendNode(node conditionNode) base.ConditionBuilder {
 	op := ""
 	if len(c.nodes) > 0 {
 		// 默认逻辑符为 AND
@@ -42,11 +90,20 @@ func (c *Condition) append(expr string, args ...any) base.ConditionBuilder {
 			c.nodes[len(c.nodes)-1].op = "" // 重置标记
 		}
 	}
-	c.nodes = append(c.nodes, conditionNode{
-		expr: expr,
-		args: args,
-		op:   op,
-	})
+	node.op = op
+	c.nodes = append(c.nodes, node)
+	return c
+}
+
+// compare 构建比较条件，值为子查询时与子查询结果比较
+func (c *Condition) compare(column string, op string, value any) base.ConditionBuilder {
+	if builder, ok := value.(base.SelectBuilder); ok {
+		return c.appendSelect(fmt.Sprintf("%s %s (", column, op), builder, ")")
+	}
+	c.append(fmt.Sprintf("%s %s ?", column, op), value)
+	if op == "=" {
+		c.nodes[len(c.nodes)-1].column = column
+	}
 	return c
 }
 
@@ -70,21 +127,36 @@ func (c *Condition) Build() (string, []any) {
 		if i > 0 {
 			part += " " + node.op + " "
 		}
-		part += node.expr
-		args = append(args, node.args...)
+		expr, nodeArgs := node.expr, node.args
+		if node.render != nil {
+			expr, nodeArgs = node.render(c.dialect)
+		}
+		part += expr
+		args = append(args, nodeArgs...)
 		sqlParts = append(sqlParts, part)
 	}
 
 	return strings.Join(sqlParts, ""), args
 }
 
+// Where 添加原生条件。支持位置参数 ?，
+// 或 :name / @name 命名参数（此时 args 为单个 map[string]any 或结构体，切片参数自动展开）。
+// 命名参数改写为 ? 占位符，执行时按方言改写；绑定失败时不添加条件，错误由 Err 及执行器返回
 func (c *Condition) Where(cond string, args ...any) base.ConditionBuilder {
-	return c
+	if len(args) == 1 && base.IsNamedSource(args[0]) && base.HasNamed(cond) {
+		sql, namedArgs, err := base.BindNamedPositional(cond, args[0])
+		if err != nil {
+			c.setErr(fmt.Errorf("bind named condition %q failed: %w", cond, err))
+			return c
+		}
+		return c.append(fmt.Sprintf("(%s)", sql), namedArgs...)
+	}
+	return c.append(fmt.Sprintf("(%s)", cond), args...)
 }
 
 func (c *Condition) WhereIf(condition bool, cond string, args ...any) base.ConditionBuilder {
 	if condition {
-		c.Where(cond, args)
+		c.Where(cond, args...)
 	}
 	return c
 }
@@ -130,7 +202,7 @@ func (c *Condition) OrIf(condition bool) base.ConditionBuilder {
 }
 
 func (c *Condition) Eq(column string, args any) base.ConditionBuilder {
-	return c.append(fmt.Sprintf("%s = ?", column), args)
+	return c.compare(column, "=", args)
 }
 
 func (c *Condition) EqIf(condition bool, column string, args any) base.ConditionBuilder {
@@ -153,7 +225,7 @@ func (c *Condition) EqAliasIf(condition bool, tableAlias string, column string,
 }
 
 func (c *Condition) Ne(column string, args any) base.ConditionBuilder {
-	return c.append(fmt.Sprintf("%s <> ?", column), args)
+	return c.compare(column, "<>", args)
 }
 
 func (c *Condition) NeIf(condition bool, column string, args any) base.ConditionBuilder {
@@ -176,7 +248,7 @@ func (c *Condition) NeAliasIf(condition bool, tableAlias string, column string,
 }
 
 func (c *Condition) Gt(column string, args any) base.ConditionBuilder {
-	return c.append(fmt.Sprintf("%s > ?", column), args)
+	return c.compare(column, ">", args)
 }
 
 func (c *Condition) GtIf(condition bool, column string, args any) base.ConditionBuilder {
@@ -199,7 +271,7 @@ func (c *Condition) GtAliasIf(condition bool, tableAlias string, column string,
 }
 
 func (c *Condition) Ge(column string, args any) base.ConditionBuilder {
-	return c.append(fmt.Sprintf("%s >= ?", column), args)
+	return c.compare(column, ">=", args)
 }
 
 func (c *Condition) GeIf(condition bool, column string, args any) base.ConditionBuilder {
@@ -222,7 +294,7 @@ func (c *Condition) GeAliasIf(condition bool, tableAlias string, column string,
 }
 
 func (c *Condition) Lt(column string, args any) base.ConditionBuilder {
-	return c.append(fmt.Sprintf("%s < ?", column), args)
+	return c.compare(column, "<", args)
 }
 
 func (c *Condition) LtIf(condition bool, column string, args any) base.ConditionBuilder {
@@ -245,7 +317,7 @@ func (c *Condition) LtAliasIf(condition bool, tableAlias string, column string,
 }
 
 func (c *Condition) Le(column string, args any) base.ConditionBuilder {
-	return c.append(fmt.Sprintf("%s <= ?", column), args)
+	return c.compare(column, "<=", args)
 }
 
 func (c *Condition) LeIf(condition bool, column string, args any) base.ConditionBuilder {
@@ -474,8 +546,7 @@ func (c *Condition) IsNotNullIf(condition bool, column string) base.ConditionBui
 }
 
 func (c *Condition) Exists(builder base.SelectBuilder) base.ConditionBuilder {
-	sql, args := builder.Build()
-	return c.append(fmt.Sprintf("EXISTS (%s)", sql), args...)
+	return c.appendSelect("EXISTS (", builder, ")")
 }
 
 func (c *Condition) ExistsIf(condition bool, builder base.SelectBuilder) base.ConditionBuilder {
@@ -498,8 +569,7 @@ func (c *Condition) ExistsAliasIf(condition bool, builder base.SelectBuilder, al
 }
 
 func (c *Condition) NotExists(builder base.SelectBuilder) base.ConditionBuilder {
-	sql, args := builder.Build()
-	return c.append(fmt.Sprintf("NOT EXISTS (%s)", sql), args...)
+	return c.appendSelect("NOT EXISTS (", builder, ")")
 }
 
 func (c *Condition) NotExistsIf(condition bool, builder base.SelectBuilder) base.ConditionBuilder {
@@ -527,7 +597,9 @@ func (c *Condition) In(column string, args ...any) base.ConditionBuilder {
 		placeholder += " ?,"
 	}
 	placeholder = placeholder[:len(placeholder)-1]
-	return c.append(fmt.Sprintf("%s IN (%s)", column, placeholder), args...)
+	c.append(fmt.Sprintf("%s IN (%s)", column, placeholder), args...)
+	c.nodes[len(c.nodes)-1].column = column
+	return c
 }
 
 func (c *Condition) InIf(condition bool, column string, args ...any) base.ConditionBuilder {
@@ -577,9 +649,36 @@ func (c *Condition) NotInAliasIf(condition bool, column string, alias string, ar
 	return c
 }
 
+func (c *Condition) InSelect(column string, builder base.SelectBuilder) base.ConditionBuilder {
+	return c.appendSelect(column+" IN (", builder, ")")
+}
+
+func (c *Condition) InSelectIf(condition bool, column string, builder base.SelectBuilder) base.ConditionBuilder {
+	if condition {
+		c.InSelect(column, builder)
+	}
+	return c
+}
+
+func (c *Condition) NotInSelect(column string, builder base.SelectBuilder) base.ConditionBuilder {
+	return c.appendSelect(column+" NOT IN (", builder, ")")
+}
+
+func (c *Condition) NotInSelectIf(condition bool, column string, builder base.SelectBuilder) base.ConditionBuilder {
+	if condition {
+		c.NotInSelect(column, builder)
+	}
+	return c
+}
+
+// Nested 添加嵌套条件，嵌套条件在添加时拷贝，构建时使用外层条件的方言
 func (c *Condition) Nested(cond base.ConditionBuilder) base.ConditionBuilder {
-	sql, args := cond.Build()
-	return c.append(fmt.Sprintf("(%s)", sql), args...)
+	c.setErr(base.BuildError(cond))
+	sub := cond.Clone()
+	return c.appendRender(func(dialect base.Dialect) (string, []any) {
+		sql, args := base.WithDialect(sub, dialect).Build()
+		return "(" + sql + ")", args
+	})
 }
 
 func (c *Condition) NestedIf(condition bool, cond base.ConditionBuilder) base.ConditionBuilder {
@@ -609,3 +708,206 @@ func (c *Condition) OnAliasIf(condition bool, aliasA string, columnA string, ali
 	}
 	return c
 }
+
+func (c *Condition) ColumnValues(column string) ([]any, bool) {
+	var (
+		values []any
+		found  bool
+	)
+	for _, node := range c.nodes {
+		if node.op == "OR" || node.op == "OR_PENDING" {
+			return nil, false
+		}
+		if !found && node.column != "" && unqualified(node.column) == unqualified(column) {
+			values, found = node.args, true
+		}
+	}
+	return values, found
+}
+
+// unqualified 去除列名的表名或别名前缀
+func unqualified(column string) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		return column[i+1:]
+	}
+	return column
+}
+
+// Dialect 指定条件使用的数据库方言，影响 JSON 与全文检索条件的渲染，构建时对嵌套条件与子查询同样生效
+func (c *Condition) Dialect(dialect base.Dialect) base.ConditionBuilder {
+	c.dialect = dialect
+	return c
+}
+
+// orDefault 未指定方言时返回全局默认方言
+func orDefault(dialect base.Dialect) base.Dialect {
+	if dialect != "" {
+		return dialect
+	}
+	return base.GetDialect()
+}
+
+// JsonEq JSON 列指定路径的值等于 value，路径形如 a.b 或 $.a.b。
+// 路径为空或非法时不添加条件，错误由 Err 及执行器返回
+func (c *Condition) JsonEq(column string, path string, value any) base.ConditionBuilder {
+	keys, err := jsonPath(path)
+	if err == nil && len(keys) == 0 {
+		err = errors.New("json eq must have path")
+	}
+	if err != nil {
+		c.setErr(err)
+		return c
+	}
+	return c.appendRender(func(dialect base.Dialect) (string, []any) {
+		if orDefault(dialect) == base.DialectPostgres {
+			return fmt.Sprintf("%s = ?", postgresJsonText(column, keys)), []any{value}
+		}
+		return fmt.Sprintf("%s->>'%s' = ?", column, mysqlJsonPath(keys)), []any{value}
+	})
+}
+
+func (c *Condition) JsonEqIf(condition bool, column string, path string, value any) base.ConditionBuilder {
+	if condition {
+		c.JsonEq(column, path, value)
+	}
+	return c
+}
+
+// JsonContains JSON 列指定路径（为空时为整个文档）包含 value，value 序列化为 JSON 后比较。
+// 路径非法或 value 序列化失败时不添加条件，错误由 Err 及执行器返回
+func (c *Condition) JsonContains(column string, path string, value any) base.ConditionBuilder {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		c.setErr(fmt.Errorf("json contains marshal value failed: %w", err))
+		return c
+	}
+	keys, err := jsonPath(path)
+	if err != nil {
+		c.setErr(err)
+		return c
+	}
+	args := []any{string(bytes)}
+	return c.appendRender(func(dialect base.Dialect) (string, []any) {
+		if orDefault(dialect) == base.DialectPostgres {
+			target := column
+			if len(keys) > 0 {
+				target = fmt.Sprintf("%s #> '%s'", column, postgresJsonPath(keys))
+			}
+			return fmt.Sprintf("%s @> CAST(? AS jsonb)", target), args
+		}
+		if len(keys) > 0 {
+			return fmt.Sprintf("JSON_CONTAINS(%s, ?, '%s')", column, mysqlJsonPath(keys)), args
+		}
+		return fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), args
+	})
+}
+
+func (c *Condition) JsonContainsIf(condition bool, column string, path string, value any) base.ConditionBuilder {
+	if condition {
+		c.JsonContains(column, path, value)
+	}
+	return c
+}
+
+// JsonHasKey JSON 列存在指定路径，路径为空或非法时不添加条件，错误由 Err 及执行器返回。
+// PostgreSQL 的 ? 操作符与占位符冲突，使用等价的 #> 路径判断
+func (c *Condition) JsonHasKey(column string, path string) base.ConditionBuilder {
+	keys, err := jsonPath(path)
+	if err == nil && len(keys) == 0 {
+		err = errors.New("json has key must have path")
+	}
+	if err != nil {
+		c.setErr(err)
+		return c
+	}
+	return c.appendRender(func(dialect base.Dialect) (string, []any) {
+		if orDefault(dialect) == base.DialectPostgres {
+			return fmt.Sprintf("%s #> '%s' IS NOT NULL", column, postgresJsonPath(keys)), nil
+		}
+		return fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', '%s')", column, mysqlJsonPath(keys)), nil
+	})
+}
+
+func (c *Condition) JsonHasKeyIf(condition bool, column string, path string) base.ConditionBuilder {
+	if condition {
+		c.JsonHasKey(column, path)
+	}
+	return c
+}
+
+// Match 全文检索。MySQL 渲染为 MATCH ... AGAINST，需要对应列的 FULLTEXT 索引；
+// PostgreSQL 渲染为 to_tsvector @@ plainto_tsquery（布尔模式使用 to_tsquery，短语模式使用 phraseto_tsquery）。
+// 没有指定列时不添加条件，错误由 Err 及执行器返回
+func (c *Condition) Match(columns []string, query string, mode base.MatchMode) base.ConditionBuilder {
+	if len(columns) == 0 {
+		c.setErr(errors.New("match must have columns"))
+		return c
+	}
+	columns = append([]string(nil), columns...)
+	return c.appendRender(func(dialect base.Dialect) (string, []any) {
+		if orDefault(dialect) == base.DialectPostgres {
+			document := columns[0]
+			if len(columns) > 1 {
+				document = fmt.Sprintf("concat_ws(' ', %s)", strings.Join(columns, ", "))
+			}
+			fn := "plainto_tsquery"
+			switch mode {
+			case base.MatchBoolean:
+				fn = "to_tsquery"
+			case base.MatchPhrase:
+				fn = "phraseto_tsquery"
+			}
+			return fmt.Sprintf("to_tsvector(%s) @@ %s(?)", document, fn), []any{query}
+		}
+		modifier, against := "IN NATURAL LANGUAGE MODE", query
+		switch mode {
+		case base.MatchBoolean:
+			modifier = "IN BOOLEAN MODE"
+		case base.MatchPhrase:
+			modifier = "IN BOOLEAN MODE"
+			against = `"` + strings.ReplaceAll(query, `"`, "") + `"`
+		}
+		return fmt.Sprintf("MATCH(%s) AGAINST (? %s)", strings.Join(columns, ", "), modifier), []any{against}
+	})
+}
+
+func (c *Condition) MatchIf(condition bool, columns []string, query string, mode base.MatchMode) base.ConditionBuilder {
+	if condition {
+		c.Match(columns, query, mode)
+	}
+	return c
+}
+
+// jsonPath 将 a.b 或 $.a.b 形式的路径拆分为键，路径为空时返回空切片
+func jsonPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, nil
+	}
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		// 路径直接拼接在 sql 中，禁止引号与反斜杠
+		if key == "" || strings.ContainsAny(key, `'"\`) {
+			return nil, fmt.Errorf("invalid json path: %s", path)
+		}
+	}
+	return keys, nil
+}
+
+// mysqlJsonPath 返回 $.a.b 形式的路径
+func mysqlJsonPath(keys []string) string {
+	return "$." + strings.Join(keys, ".")
+}
+
+// postgresJsonPath 返回 {a,b} 形式的路径
+func postgresJsonPath(keys []string) string {
+	return "{" + strings.Join(keys, ",") + "}"
+}
+
+// postgresJsonText 返回以文本形式读取路径值的表达式
+func postgresJsonText(column string, keys []string) string {
+	if len(keys) == 1 {
+		return fmt.Sprintf("%s->>'%s'", column, keys[0])
+	}
+	return fmt.Sprintf("%s #>> '%s'", column, postgresJsonPath(keys))
+}
@@ -1,16 +1,22 @@
 package dql
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/Cooooing/cutil/query/base"
 )
 
+// aggregateColumnRegexp 匹配包含聚合函数或窗口函数的查询列
+var aggregateColumnRegexp = regexp.MustCompile(`(?i)\b(?:COUNT|SUM|AVG|MIN|MAX|GROUP_CONCAT|STRING_AGG|ARRAY_AGG|JSON_AGG|JSONB_AGG|JSON_ARRAYAGG|JSON_OBJECTAGG|BIT_AND|BIT_OR|BIT_XOR|BOOL_AND|BOOL_OR|STDDEV|STDDEV_POP|STDDEV_SAMP|VARIANCE|VAR_POP|VAR_SAMP)\s*\(|\bOVER\s*[(\w]`)
+
 type Select struct {
-	columns    []string
+	columns    []columnNode
 	table      string
 	tableAlias string
+	fromQuery  base.SelectBuilder
 	joins      []joinNode
 	whereCond  base.ConditionBuilder
 	groupBy    []string
@@ -18,6 +24,12 @@ type Select struct {
 	orderBy    []string
 	limit      int
 	offset     int
+	dialect    base.Dialect
+	lock       base.LockMode
+	skipLocked bool
+	noWait     bool
+	scopes     base.Scopes
+	physical   string // 分表路由后的物理表名
 }
 
 func NewSelect() *Select {
@@ -29,12 +41,61 @@ func NewSelect() *Select {
 
 func NewExistSelect() *Select {
 	return &Select{
-		columns: []string{"1"},
+		columns: []columnNode{{column: "1"}},
 		limit:   -1,
 		offset:  -1,
 	}
 }
 
+// Clone 深拷贝查询，包括条件与子查询
+func (s *Select) Clone() base.SelectBuilder {
+	clone := *s
+	clone.columns = make([]columnNode, len(s.columns))
+	for i, col := range s.columns {
+		if col.subQuery != nil {
+			col.subQuery = col.subQuery.Clone()
+		}
+		clone.columns[i] = col
+	}
+	if s.fromQuery != nil {
+		clone.fromQuery = s.fromQuery.Clone()
+	}
+	clone.joins = make([]joinNode, len(s.joins))
+	for i, j := range s.joins {
+		if j.on != nil {
+			j.on = j.on.Clone()
+		}
+		if j.subQuery != nil {
+			j.subQuery = j.subQuery.Clone()
+		}
+		clone.joins[i] = j
+	}
+	if s.whereCond != nil {
+		clone.whereCond = s.whereCond.Clone()
+	}
+	if s.havingCond != nil {
+		clone.havingCond = s.havingCond.Clone()
+	}
+	clone.groupBy = append([]string(nil), s.groupBy...)
+	clone.orderBy = append([]string(nil), s.orderBy...)
+	clone.scopes = append(base.Scopes(nil), s.scopes...)
+	return &clone
+}
+
+// Err 返回查询列、子查询、连接与过滤条件中记录的构建错误
+func (s *Select) Err() error {
+	var errs []error
+	for _, col := range s.columns {
+		errs = append(errs, base.BuildError(col.subQuery))
+	}
+	errs = append(errs, base.BuildError(s.fromQuery))
+	for _, j := range s.joins {
+		errs = append(errs, base.BuildError(j.on), base.BuildError(j.subQuery))
+	}
+	errs = append(errs, base.BuildError(s.whereCond), base.BuildError(s.havingCond))
+	return errors.Join(errs...)
+}
+
 func (s *Select) GetSql() string {
 	sql, _ := s.Build()
 	return sql
@@ -46,57 +107,198 @@ func (s *Select) GetArgs() []any {
 }
 
 func (s *Select) Build() (string, []any) {
-	if len(s.columns) == 0 {
-		s.columns = []string{"*"}
-	}
 	var sqlParts []string
-	var args []any
 
 	// Columns
-	if len(s.columns) == 0 {
-		sqlParts = append(sqlParts, "SELECT *")
+	columnsSQL, args := s.buildColumns()
+	sqlParts = append(sqlParts, "SELECT "+columnsSQL)
+
+	bodySQL, bodyArgs := s.buildBody()
+	if bodySQL != "" {
+		sqlParts = append(sqlParts, bodySQL)
+	}
+	args = append(args, bodyArgs...)
+
+	// ORDER BY
+	if len(s.orderBy) > 0 {
+		sqlParts = append(sqlParts, "ORDER BY "+strings.Join(s.orderBy, ", "))
+	}
+
+	// LIMIT / OFFSET
+	if s.limit >= 0 {
+		sqlParts = append(sqlParts, fmt.Sprintf("LIMIT %d", s.limit))
+	}
+	if s.offset >= 0 {
+		sqlParts = append(sqlParts, fmt.Sprintf("OFFSET %d", s.offset))
+	}
+
+	// FOR UPDATE / FOR SHARE
+	if lockSQL := s.buildLock(); lockSQL != "" {
+		sqlParts = append(sqlParts, lockSQL)
+	}
+
+	return strings.Join(sqlParts, " "), args
+}
+
+// buildLock 按方言构建行锁子句
+func (s *Select) buildLock() string {
+	dialect := s.dialect
+	if dialect == "" {
+		dialect = base.GetDialect()
+	}
+
+	var lockSQL string
+	switch s.lock {
+	case base.LockForUpdate:
+		lockSQL = "FOR UPDATE"
+	case base.LockForShare:
+		// MySQL 5.7 仅支持 LOCK IN SHARE MODE，且不支持 SKIP LOCKED / NOWAIT
+		if dialect == base.DialectMySQL && !s.skipLocked && !s.noWait {
+			return "LOCK IN SHARE MODE"
+		}
+		lockSQL = "FOR SHARE"
+	default:
+		return ""
+	}
+
+	if s.skipLocked {
+		lockSQL += " SKIP LOCKED"
+	} else if s.noWait {
+		lockSQL += " NOWAIT"
+	}
+	return lockSQL
+}
+
+// BuildCount 构建计数查询。去除 ORDER BY、LIMIT/OFFSET 及查询列，直接 COUNT(*)；
+// 包含 GROUP BY、HAVING 或 DISTINCT 时无法改写，退化为子查询包装。
+//
+// 参数:
+//   - limit: 计数上限，大于 0 时最多统计 limit 行
+//
+// 返回:
+//   - string: 计数sql
+//   - []any: 参数
+func (s *Select) BuildCount(limit int) (string, []any) {
+	bodySQL, args := s.buildBody()
+
+	var rowsSQL string
+	if s.countNeedWrap() {
+		columnsSQL, columnArgs := s.buildColumns()
+		rowsSQL = strings.TrimSpace(fmt.Sprintf("SELECT %s %s", columnsSQL, bodySQL))
+		args = append(columnArgs, args...)
+	} else if limit > 0 {
+		rowsSQL = strings.TrimSpace("SELECT 1 " + bodySQL)
 	} else {
-		sqlParts = append(sqlParts, "SELECT "+strings.Join(s.columns, ", "))
+		return strings.TrimSpace("SELECT COUNT(*) AS total " + bodySQL), args
+	}
+
+	if limit > 0 {
+		rowsSQL = fmt.Sprintf("%s LIMIT %d", rowsSQL, limit)
+	}
+	return fmt.Sprintf("SELECT COUNT(*) AS total FROM (%s) AS t", rowsSQL), args
+}
+
+// BuildExists 构建存在性查询。去除查询列、ORDER BY、LIMIT/OFFSET 及锁子句，改写为 SELECT 1 ... LIMIT 1
+//
+// 返回:
+//   - string: 存在性查询sql
+//   - []any: 参数
+func (s *Select) BuildExists() (string, []any) {
+	bodySQL, args := s.buildBody()
+	return strings.TrimSpace("SELECT 1 "+bodySQL) + " LIMIT 1", args
+}
+
+// countNeedWrap 判断计数查询是否需要子查询包装：分组、HAVING、DISTINCT，以及包含聚合函数或窗口函数的查询列
+// （无 GROUP BY 的聚合查询只返回一行，改写为 COUNT(*) 会统计原始行数）
+func (s *Select) countNeedWrap() bool {
+	if len(s.groupBy) > 0 || s.havingCond != nil {
+		return true
+	}
+	for _, col := range s.columns {
+		if col.subQuery != nil {
+			continue
+		}
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(col.column)), "DISTINCT ") || aggregateColumnRegexp.MatchString(col.column) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildColumns 构建查询列，标量子查询的参数按列顺序合并
+func (s *Select) buildColumns() (string, []any) {
+	if len(s.columns) == 0 {
+		return "*", nil
 	}
+	var args []any
+	columns := make([]string, 0, len(s.columns))
+	for _, col := range s.columns {
+		if col.subQuery == nil {
+			columns = append(columns, col.column)
+			continue
+		}
+		subSQL, subArgs := base.WithDialect(col.subQuery, s.dialect).Build()
+		args = append(args, subArgs...)
+		if col.alias != "" {
+			columns = append(columns, fmt.Sprintf("(%s) AS %s", subSQL, col.alias))
+		} else {
+			columns = append(columns, fmt.Sprintf("(%s)", subSQL))
+		}
+	}
+	return strings.Join(columns, ", "), args
+}
+
+// buildBody 构建 FROM、JOIN、WHERE、GROUP BY、HAVING 部分
+func (s *Select) buildBody() (string, []any) {
+	var sqlParts []string
+	var args []any
 
 	// FROM
-	if s.table != "" {
+	if s.fromQuery != nil {
+		subSQL, subArgs := base.WithDialect(s.fromQuery, s.dialect).Build()
+		args = append(args, subArgs...)
+		sqlParts = append(sqlParts, fmt.Sprintf("FROM (%s) AS %s", subSQL, s.tableAlias))
+	} else if s.table != "" {
 		if s.tableAlias != "" {
-			sqlParts = append(sqlParts, fmt.Sprintf("FROM %s AS %s", s.table, s.tableAlias))
+			sqlParts = append(sqlParts, fmt.Sprintf("FROM %s AS %s", s.fromTable(), s.tableAlias))
 		} else {
-			sqlParts = append(sqlParts, fmt.Sprintf("FROM %s", s.table))
+			sqlParts = append(sqlParts, fmt.Sprintf("FROM %s", s.fromTable()))
 		}
 	}
 
 	// JOIN
 	for _, j := range s.joins {
 		var joinSQL string
+		onSQL, onArgs := base.WithDialect(j.on, s.dialect).Build()
 		if j.subQuery != nil {
-			subSQL, subArgs := j.subQuery.Build()
+			subSQL, subArgs := base.WithDialect(j.subQuery, s.dialect).Build()
 			args = append(args, subArgs...)
 			if j.alias != "" {
-				joinSQL = fmt.Sprintf("%s (%s) AS %s ON %s", j.joinType, subSQL, j.alias, j.on.GetSql())
+				joinSQL = fmt.Sprintf("%s (%s) AS %s ON %s", j.joinType, subSQL, j.alias, onSQL)
 			} else {
-				joinSQL = fmt.Sprintf("%s (%s) ON %s", j.joinType, subSQL, j.on.GetSql())
+				joinSQL = fmt.Sprintf("%s (%s) ON %s", j.joinType, subSQL, onSQL)
 			}
 		} else {
 			if j.alias != "" {
-				joinSQL = fmt.Sprintf("%s %s AS %s ON %s", j.joinType, j.table, j.alias, j.on.GetSql())
+				joinSQL = fmt.Sprintf("%s %s AS %s ON %s", j.joinType, j.table, j.alias, onSQL)
 			} else {
-				joinSQL = fmt.Sprintf("%s %s ON %s", j.joinType, j.table, j.on.GetSql())
+				joinSQL = fmt.Sprintf("%s %s ON %s", j.joinType, j.table, onSQL)
 			}
 		}
 		sqlParts = append(sqlParts, joinSQL)
-		args = append(args, j.on.GetArgs()...)
+		args = append(args, onArgs...)
 	}
 
 	// WHERE
+	var whereSQL string
+	var whereArgs []any
 	if s.whereCond != nil {
-		whereSQL, whereArgs := s.whereCond.Build()
-		if whereSQL != "" {
-			sqlParts = append(sqlParts, "WHERE "+whereSQL)
-			args = append(args, whereArgs...)
-		}
+		whereSQL, whereArgs = base.WithDialect(s.whereCond, s.dialect).Build()
+	}
+	whereSQL, whereArgs = s.scopes.Merge(whereSQL, whereArgs, s.scopeQualifier())
+	if whereSQL != "" {
+		sqlParts = append(sqlParts, "WHERE "+whereSQL)
+		args = append(args, whereArgs...)
 	}
 
 	// GROUP BY
@@ -106,43 +308,48 @@ func (s *Select) Build() (string, []any) {
 
 	// HAVING
 	if s.havingCond != nil {
-		havingSQL, havingArgs := s.havingCond.Build()
+		havingSQL, havingArgs := base.WithDialect(s.havingCond, s.dialect).Build()
 		if havingSQL != "" {
 			sqlParts = append(sqlParts, "HAVING "+havingSQL)
 			args = append(args, havingArgs...)
 		}
 	}
 
-	// ORDER BY
-	if len(s.orderBy) > 0 {
-		sqlParts = append(sqlParts, "ORDER BY "+strings.Join(s.orderBy, ", "))
-	}
-
-	// LIMIT / OFFSET
-	if s.limit >= 0 {
-		sqlParts = append(sqlParts, fmt.Sprintf("LIMIT %d", s.limit))
-	}
-	if s.offset >= 0 {
-		sqlParts = append(sqlParts, fmt.Sprintf("OFFSET %d", s.offset))
-	}
-
 	return strings.Join(sqlParts, " "), args
 }
 
 func (s *Select) From(table string) base.SelectBuilder {
 	s.table = table
 	s.tableAlias = ""
+	s.fromQuery = nil
 	return s
 }
 
 func (s *Select) FromAlias(table string, alias string) base.SelectBuilder {
 	s.table = table
 	s.tableAlias = alias
+	s.fromQuery = nil
+	return s
+}
+
+// FromSelect 从子查询中查询，子查询必须指定别名
+func (s *Select) FromSelect(builder base.SelectBuilder, alias string) base.SelectBuilder {
+	s.table = ""
+	s.tableAlias = alias
+	s.fromQuery = builder
 	return s
 }
 
 func (s *Select) Columns(columns ...string) base.SelectBuilder {
-	s.columns = append(s.columns, columns...)
+	for _, col := range columns {
+		s.columns = append(s.columns, columnNode{column: col})
+	}
+	return s
+}
+
+// ColumnSelect 添加标量子查询列
+func (s *Select) ColumnSelect(builder base.SelectBuilder, alias string) base.SelectBuilder {
+	s.columns = append(s.columns, columnNode{subQuery: builder, alias: alias})
 	return s
 }
 
@@ -223,6 +430,109 @@ func (s *Select) Offset(offset int) base.SelectBuilder {
 	return s
 }
 
+// Scope 追加范围限定条件 column = value
+func (s *Select) Scope(column string, value any) {
+	s.scopes.Set(column, value)
+}
+
+// scopeQualifier 范围限定列的前缀：优先使用表别名，存在连接时使用表名避免列名歧义
+func (s *Select) scopeQualifier() string {
+	if s.tableAlias != "" {
+		return s.tableAlias
+	}
+	// 兼容 From("users u") 写法
+	if fields := strings.Fields(s.table); len(fields) > 1 {
+		return fields[len(fields)-1]
+	}
+	if len(s.joins) > 0 {
+		return s.fromTable()
+	}
+	return ""
+}
+
+// fromTable 返回查询的表，已路由到物理表时替换逻辑表名
+func (s *Select) fromTable() string {
+	if s.physical == "" {
+		return s.table
+	}
+	if fields := strings.Fields(s.table); len(fields) > 1 {
+		return s.physical + " " + strings.Join(fields[1:], " ")
+	}
+	return s.physical
+}
+
+// LogicalTable 返回查询的逻辑表名，从子查询中查询时为空
+func (s *Select) LogicalTable() string {
+	if fields := strings.Fields(s.table); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// ShardValues 返回 where 条件中 column 的取值
+func (s *Select) ShardValues(column string) ([]any, bool) {
+	if s.whereCond == nil {
+		return nil, false
+	}
+	return s.whereCond.ColumnValues(column)
+}
+
+func (s *Select) PhysicalTable(table string) {
+	s.physical = table
+}
+
+// Dialect 指定数据库方言，影响锁子句的渲染，构建时同样应用于条件与子查询
+func (s *Select) Dialect(dialect base.Dialect) base.SelectBuilder {
+	s.dialect = dialect
+	return s
+}
+
+func (s *Select) ForUpdate() base.SelectBuilder {
+	s.lock = base.LockForUpdate
+	return s
+}
+
+func (s *Select) ForShare() base.SelectBuilder {
+	s.lock = base.LockForShare
+	return s
+}
+
+// SkipLocked 跳过已被锁定的行，需配合 ForUpdate 或 ForShare 使用
+func (s *Select) SkipLocked() base.SelectBuilder {
+	s.skipLocked = true
+	s.noWait = false
+	return s
+}
+
+// NoWait 行已被锁定时立即报错，需配合 ForUpdate 或 ForShare 使用
+func (s *Select) NoWait() base.SelectBuilder {
+	s.noWait = true
+	s.skipLocked = false
+	return s
+}
+
+func (s *Select) GetLock() base.LockMode {
+	return s.lock
+}
+
+func (s *Select) GetOrderBy() []string {
+	return s.orderBy
+}
+
+func (s *Select) GetLimit() int {
+	return s.limit
+}
+
+func (s *Select) GetOffset() int {
+	return s.offset
+}
+
+type columnNode struct {
+	column   string
+	subQuery base.SelectBuilder
+	alias    string
+}
+
 type joinNode struct {
 	joinType string
 	table    string
@@ -1,10 +1,13 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync"
 
+	base2 "github.com/Cooooing/cutil/base"
 	"github.com/Cooooing/cutil/base/logger"
 	"github.com/Cooooing/cutil/query/base"
 )
@@ -21,24 +24,170 @@ func UnDebug() {
 
 type Executor[T any] struct {
 	db      *sql.DB
+	tx      *sql.Tx
 	builder base.Builder
 	debug   bool
+	ctx     context.Context
+
+	// 分页选项
+	skipCount       bool // 不查询总数
+	concurrentCount bool // 并发查询总数与数据
+	countLimit      int  // 计数上限，大于 0 时生效
+
+	retry   *RetryPolicy // 瞬时错误重试策略
+	dialect base.Dialect // 数据库方言，未指定时使用全局默认方言
+	audit   AuditSink    // 变更审计，为 nil 时不审计
 }
 
+// WithExecutor 创建执行器。执行器使用构建器的拷贝，注入的范围限定、锁等不影响传入的构建器，构建器可作为模板复用
 func WithExecutor[T any](db *sql.DB, builder base.Builder) *Executor[T] {
 	return &Executor[T]{
 		db:      db,
-		builder: builder,
+		builder: cloneBuilder(builder),
+		debug:   false,
+	}
+}
+
+// WithTxExecutor 创建在事务中执行的执行器
+func WithTxExecutor[T any](tx *sql.Tx, builder base.Builder) *Executor[T] {
+	return &Executor[T]{
+		tx:      tx,
+		builder: cloneBuilder(builder),
 		debug:   false,
 	}
 }
 
+// cloneBuilder 拷贝构建器，不支持拷贝的构建器原样返回
+func cloneBuilder(builder base.Builder) base.Builder {
+	switch b := builder.(type) {
+	case base.SelectBuilder:
+		return b.Clone()
+	case base.UpdateBuilder:
+		return b.Clone()
+	case base.InsertBuilder:
+		return b.Clone()
+	case base.DeleteBuilder:
+		return b.Clone()
+	case base.BulkUpdateBuilder:
+		return b.Clone()
+	case base.ConditionBuilder:
+		return b.Clone()
+	}
+	return builder
+}
+
+// conn 返回当前执行使用的连接，存在事务时使用事务
+func (e *Executor[T]) conn() base.Queryer {
+	if e.tx != nil {
+		return rebind(e.tx, e.getDialect())
+	}
+	return rebind(e.db, e.getDialect())
+}
+
+// WithContext 设置执行上下文，租户模型从上下文中读取租户 ID
+func (e *Executor[T]) WithContext(ctx context.Context) *Executor[T] {
+	e.ctx = ctx
+	return e
+}
+
 func (e *Executor[T]) Debug() *Executor[T] {
 	e.debug = true
 	return e
 }
 
+// SkipCount 分页查询时不查询总数
+func (e *Executor[T]) SkipCount() *Executor[T] {
+	e.skipCount = true
+	return e
+}
+
+// ConcurrentCount 分页查询时并发查询总数与数据
+func (e *Executor[T]) ConcurrentCount() *Executor[T] {
+	e.concurrentCount = true
+	return e
+}
+
+// CountLimit 分页查询时最多计数 limit 行，超出时总数为 limit 并标记 TotalCapped（如 "10000+"）
+func (e *Executor[T]) CountLimit(limit int) *Executor[T] {
+	e.countLimit = limit
+	return e
+}
+
+// Retry 设置瞬时错误（死锁、序列化失败）重试策略，为 nil 时使用默认策略。
+// 事务内的执行器不会单独重试语句，需通过 TransactionWithRetry 重新执行整个事务
+func (e *Executor[T]) Retry(policy *RetryPolicy) *Executor[T] {
+	if policy == nil {
+		policy = DefaultRetryPolicy()
+	}
+	e.retry = policy
+	return e
+}
+
+// do 执行数据库操作，按重试策略重试并对错误进行分类
+func (e *Executor[T]) do(fn func() error) error {
+	if e.retry != nil && e.tx == nil {
+		return e.retry.Do(fn)
+	}
+	return base.TranslateError(fn())
+}
+
+// Dialect 指定执行器使用的数据库方言，同时用于构建语句与改写占位符
+func (e *Executor[T]) Dialect(dialect base.Dialect) *Executor[T] {
+	e.dialect = dialect
+	return e
+}
+
+func (e *Executor[T]) getDialect() base.Dialect {
+	if e.dialect != "" {
+		return e.dialect
+	}
+	return base.GetDialect()
+}
+
+// prepare 执行前注入范围限定与方言，并检查构建器记录的构建错误（包括范围限定与构建器冲突）
+func (e *Executor[T]) prepare() error {
+	if err := e.applyScope(); err != nil {
+		return err
+	}
+	e.applyDialect()
+	return base.BuildError(e.builder)
+}
+
+// applyDialect 将执行器指定的方言传给构建器，使锁子句、JSON 与全文检索条件及批量更新按连接的方言渲染。
+// 执行器未指定方言时保留构建器自身的方言
+func (e *Executor[T]) applyDialect() {
+	if e.dialect == "" {
+		return
+	}
+	switch b := e.builder.(type) {
+	case base.SelectBuilder:
+		b.Dialect(e.dialect)
+	case base.UpdateBuilder:
+		b.Dialect(e.dialect)
+	case base.InsertBuilder:
+		b.Dialect(e.dialect)
+	case base.DeleteBuilder:
+		b.Dialect(e.dialect)
+	case base.BulkUpdateBuilder:
+		b.Dialect(e.dialect)
+	case base.ConditionBuilder:
+		b.Dialect(e.dialect)
+	}
+}
+
+// Build 构建执行时的sql，包含注入的范围限定，占位符按执行器方言改写
+func (e *Executor[T]) Build() (string, []any, error) {
+	if err := e.prepare(); err != nil {
+		return "", nil, err
+	}
+	s, args := e.builder.Build()
+	return base.Rebind(e.getDialect(), s), args, nil
+}
+
 func (e *Executor[T]) Log() {
+	if err := e.prepare(); err != nil {
+		logger.Warn("apply scope failed: %v", err)
+	}
 	s, args := e.builder.Build()
 	logger.Info("\nSQL: %s\nArgs:%+v", s, args)
 }
@@ -46,32 +195,109 @@ func (e *Executor[T]) Log() {
 func (e *Executor[T]) log(s string, args ...any) {
 	if e.debug || debug {
 		logger.Info("\nSQL: %s\nArgs:%+v", s, args)
+		e.warnFullScan(s, args...)
 	}
 }
 
 func (e *Executor[T]) Exec() (sql.Result, error) {
+	if err := e.prepare(); err != nil {
+		return nil, err
+	}
+	if chunked, ok := e.builder.(base.ChunkedBuilder); ok {
+		if e.audit != nil {
+			return nil, ErrAuditNotSupported
+		}
+		return e.execChunks(chunked.BuildChunks())
+	}
 	s, args := e.builder.Build()
+	if target, ok := e.builder.(base.TargetBuilder); ok && e.audit != nil {
+		return e.auditExec(target, s, args)
+	}
 	e.log(s, args...)
-	return e.db.Exec(s, args...)
+	var result sql.Result
+	err := e.do(func() (err error) {
+		result, err = e.conn().Exec(s, args...)
+		return err
+	})
+	return result, err
+}
+
+// execChunks 执行分批语句，多条语句时在同一事务中执行，返回合计的影响行数
+func (e *Executor[T]) execChunks(statements []base.Statement) (sql.Result, error) {
+	if len(statements) == 1 {
+		e.log(statements[0].Sql, statements[0].Args...)
+		var result sql.Result
+		err := e.do(func() (err error) {
+			result, err = e.conn().Exec(statements[0].Sql, statements[0].Args...)
+			return err
+		})
+		return result, err
+	}
+	var result sumResult
+	fn := func(tx *sql.Tx) error {
+		result = 0
+		for _, statement := range statements {
+			e.log(statement.Sql, statement.Args...)
+			r, err := rebind(tx, e.getDialect()).Exec(statement.Sql, statement.Args...)
+			if err != nil {
+				return err
+			}
+			affected, err := r.RowsAffected()
+			if err != nil {
+				return err
+			}
+			result += sumResult(affected)
+		}
+		return nil
+	}
+	if e.tx != nil {
+		return result, base.TranslateError(fn(e.tx))
+	}
+	if e.retry != nil {
+		return result, TransactionWithRetry(e.db, e.retry, fn)
+	}
+	return result, Transaction(e.db, fn)
+}
+
+// sumResult 多条语句执行的合计结果
+type sumResult int64
+
+func (r sumResult) LastInsertId() (int64, error) {
+	return 0, errors.New("LastInsertId is not supported by multiple statements")
+}
+
+func (r sumResult) RowsAffected() (int64, error) {
+	return int64(r), nil
 }
 
 func (e *Executor[T]) Raw() (*sql.Rows, error) {
+	if err := e.prepare(); err != nil {
+		return nil, err
+	}
 	s, args := e.builder.Build()
 	e.log(s, args...)
-	return e.db.Query(s, args...)
+	var rows *sql.Rows
+	err := e.do(func() (err error) {
+		rows, err = e.conn().Query(s, args...)
+		return err
+	})
+	return rows, err
 }
 
 func (e *Executor[T]) First() (*T, error) {
 	if _, ok := e.builder.(base.SelectBuilder); ok {
+		if err := e.prepare(); err != nil {
+			return nil, err
+		}
 		s, args := e.builder.Build()
 		e.log(s, args...)
 		s = fmt.Sprintf(`SELECT t.* FROM (%s) AS t LIMIT %d`, s, 1)
-		t, err := base.Raws2Struct[T](e.db, s, args...)
+		t, err := e.list(s, args...)
 		if err != nil {
 			return nil, err
 		}
 		if len(t) == 0 {
-			return nil, errors.New("no data")
+			return nil, base.ErrNotFound
 		}
 		return t[0], err
 	}
@@ -80,29 +306,164 @@ func (e *Executor[T]) First() (*T, error) {
 
 func (e *Executor[T]) List() ([]*T, error) {
 	if _, ok := e.builder.(base.SelectBuilder); ok {
+		if err := e.prepare(); err != nil {
+			return nil, err
+		}
 		s, args := e.builder.Build()
 		e.log(s, args...)
-		return base.Raws2Struct[T](e.db, s, args...)
+		return e.list(s, args...)
 	}
 	return nil, base.ErrorExecutorNotSupportSelect
 }
 
-func (e *Executor[any]) Count() (int, error) {
-	if _, ok := e.builder.(base.SelectBuilder); ok {
-		s, args := e.builder.Build()
-		e.log(s, args...)
-		return QueryCount(e.db, s, args...)
+// list 查询数据并映射为结构体列表
+func (e *Executor[T]) list(s string, args ...any) ([]*T, error) {
+	var list []*T
+	err := e.do(func() (err error) {
+		list, err = base.Raws2Struct[T](e.conn(), s, args...)
+		return err
+	})
+	return list, err
+}
+
+func (e *Executor[T]) Count() (int, error) {
+	if sb, ok := e.builder.(base.SelectBuilder); ok {
+		if err := e.prepare(); err != nil {
+			return 0, err
+		}
+		return e.count(sb, -1)
 	}
 	return 0, base.ErrorExecutorNotSupportSelect
 }
 
 func (e *Executor[T]) Page(page base.PageReqInterface) (base.PageRespInterface[T], error) {
-	if _, ok := e.builder.(base.SelectBuilder); ok {
-		s, args := e.builder.Build()
+	sb, ok := e.builder.(base.SelectBuilder)
+	if !ok {
+		return nil, base.ErrorExecutorNotSupportSelect
+	}
+	if err := e.prepare(); err != nil {
+		return nil, err
+	}
+	if page == nil {
+		page = getDefaultPageReq()
+	}
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	pageResp := getDefaultPageResp[T]()
+	pageResp.SetPageReq(page)
+
+	countFunc := func() error {
+		if e.skipCount {
+			return nil
+		}
+		limit := -1
+		if e.countLimit > 0 {
+			limit = e.countLimit + 1
+		}
+		total, err := e.count(sb, limit)
+		if err != nil {
+			return err
+		}
+		if e.countLimit > 0 && total > e.countLimit {
+			total = e.countLimit
+			if capped, ok := pageResp.(base.PageRespCappedInterface); ok {
+				capped.SetTotalCapped(true)
+			}
+		}
+		pageResp.SetTotal(total)
+		return nil
+	}
+	listFunc := func() error {
+		s, args := sb.Build()
+		s = getLimitOffsetQuery(page, s)
 		e.log(s, args...)
-		return PageQueryForStructWithLimitOffset[T](e.db, page, s, args...)
+		list, err := e.list(s, args...)
+		if err != nil {
+			return err
+		}
+		pageResp.SetList(list)
+		return nil
 	}
-	return nil, base.ErrorExecutorNotSupportSelect
+
+	// 事务内的连接不支持并发查询
+	if e.concurrentCount && !e.skipCount && e.tx == nil {
+		var (
+			wg       sync.WaitGroup
+			countErr error
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			countErr = countFunc()
+		}()
+		listErr := listFunc()
+		wg.Wait()
+		if err := errors.Join(countErr, listErr); err != nil {
+			return nil, err
+		}
+		return pageResp, nil
+	}
+
+	if err := countFunc(); err != nil {
+		return nil, err
+	}
+	if err := listFunc(); err != nil {
+		return nil, err
+	}
+	return pageResp, nil
+}
+
+// count 使用改写后的计数查询统计总数
+func (e *Executor[T]) count(sb base.SelectBuilder, limit int) (int, error) {
+	var total int
+	s, args := sb.BuildCount(limit)
+	e.log(s, args...)
+	err := e.do(func() error {
+		return e.conn().QueryRow(s, args...).Scan(&total)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// LockFirst 加锁查询第一条数据，必须在事务中执行。未指定锁模式时默认 FOR UPDATE
+func (e *Executor[T]) LockFirst() (*T, error) {
+	list, err := e.lockQuery(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, base.ErrNotFound
+	}
+	return list[0], nil
+}
+
+// LockList 加锁查询最多 limit 条数据，必须在事务中执行。未指定锁模式时默认 FOR UPDATE。
+// 配合 SkipLocked 可实现任务队列领取（SELECT ... FOR UPDATE SKIP LOCKED LIMIT n）
+func (e *Executor[T]) LockList(limit int) ([]*T, error) {
+	return e.lockQuery(limit)
+}
+
+func (e *Executor[T]) lockQuery(limit int) ([]*T, error) {
+	sb, ok := e.builder.(base.SelectBuilder)
+	if !ok {
+		return nil, base.ErrorExecutorNotSupportSelect
+	}
+	if e.tx == nil {
+		return nil, base.ErrorExecutorLockNeedTx
+	}
+	if err := e.prepare(); err != nil {
+		return nil, err
+	}
+	if sb.GetLock() == base.LockNone {
+		sb.ForUpdate()
+	}
+	// 锁子句不能位于子查询中，直接在原查询上限制行数
+	s, args := sb.Limit(limit).Build()
+	e.log(s, args...)
+	return e.list(s, args...)
 }
 
 func (e *Executor[T]) Delete() (int64, error) {
@@ -115,3 +476,150 @@ func (e *Executor[T]) Delete() (int64, error) {
 	}
 	return 0, base.ErrorExecutorNotSupportDelete
 }
+
+// Exists 查询是否存在满足条件的数据，执行 SELECT 1 ... LIMIT 1（不包含查询列、排序与锁子句）
+//
+// 返回:
+//   - bool: 是否存在
+//   - error: 查询失败的错误信息
+func (e *Executor[T]) Exists() (bool, error) {
+	if sb, ok := e.builder.(base.SelectBuilder); ok {
+		if err := e.prepare(); err != nil {
+			return false, err
+		}
+		s, args := sb.BuildExists()
+		e.log(s, args...)
+		var exists bool
+		err := e.do(func() error {
+			rows, err := e.conn().Query(s, args...)
+			if err != nil {
+				return err
+			}
+			defer func(rows *sql.Rows) {
+				if err := rows.Close(); err != nil {
+					logger.Error("close rows failed: %w", err)
+				}
+			}(rows)
+			exists = rows.Next()
+			return rows.Err()
+		})
+		return exists, err
+	}
+	return false, base.ErrorExecutorNotSupportSelect
+}
+
+// 结果转换函数，返回值类型与 Executor 的泛型类型不同。（解决go中方法不能增加泛型的问题）
+
+// Pluck 查询单列数据
+//
+// 参数:
+//   - e: 执行器
+//   - column: 列名
+//
+// 返回:
+//   - []V: 列值集合
+//   - error: 查询失败的错误信息
+func Pluck[T any, V any](e *Executor[T], column string) ([]V, error) {
+	if _, ok := e.builder.(base.SelectBuilder); !ok {
+		return nil, base.ErrorExecutorNotSupportSelect
+	}
+	if err := e.prepare(); err != nil {
+		return nil, err
+	}
+	s, args := e.builder.Build()
+	s = fmt.Sprintf(`SELECT t.%s FROM (%s) AS t`, column, s)
+	e.log(s, args...)
+	var list []V
+	err := e.do(func() error {
+		rows, err := e.conn().Query(s, args...)
+		if err != nil {
+			return err
+		}
+		defer func(rows *sql.Rows) {
+			if err := rows.Close(); err != nil {
+				logger.Error("close rows failed: %w", err)
+			}
+		}(rows)
+
+		list = make([]V, 0)
+		for rows.Next() {
+			var v V
+			if err := rows.Scan(&v); err != nil {
+				return err
+			}
+			list = append(list, v)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Scalar 查询单个值，适用于聚合查询（如 COUNT、MAX、SUM），取结果第一行第一列
+//
+// 参数:
+//   - e: 执行器
+//
+// 返回:
+//   - V: 查询结果
+//   - error: 查询失败的错误信息
+func Scalar[T any, V any](e *Executor[T]) (V, error) {
+	var v V
+	if _, ok := e.builder.(base.SelectBuilder); !ok {
+		return v, base.ErrorExecutorNotSupportSelect
+	}
+	if err := e.prepare(); err != nil {
+		return v, err
+	}
+	s, args := e.builder.Build()
+	e.log(s, args...)
+	err := e.do(func() error {
+		return e.conn().QueryRow(s, args...).Scan(&v)
+	})
+	return v, err
+}
+
+// ToMap 查询数据并按指定键转为 map，键重复时后者覆盖前者
+//
+// 参数:
+//   - e: 执行器
+//   - keyFunc: 键提取函数
+//
+// 返回:
+//   - map[K]*T: 查询结果
+//   - error: 查询失败的错误信息
+func ToMap[T any, K comparable](e *Executor[T], keyFunc base2.Function[*T, K]) (map[K]*T, error) {
+	list, err := e.List()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[K]*T, len(list))
+	for _, item := range list {
+		result[keyFunc(item)] = item
+	}
+	return result, nil
+}
+
+// GroupInto 查询数据并按指定键分组
+//
+// 参数:
+//   - e: 执行器
+//   - classifier: 分组键提取函数
+//
+// 返回:
+//   - map[K][]*T: 分组结果
+//   - error: 查询失败的错误信息
+func GroupInto[T any, K comparable](e *Executor[T], classifier base2.Function[*T, K]) (map[K][]*T, error) {
+	list, err := e.List()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[K][]*T)
+	for _, item := range list {
+		key := classifier(item)
+		result[key] = append(result[key], item)
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Cooooing/cutil/base/logger"
+	"github.com/Cooooing/cutil/query/base"
+)
+
+// explainedCacheSize 已检查sql的缓存上限，超过后清空重新记录
+const explainedCacheSize = 4096
+
+var (
+	// fullScanThreshold 调试模式下全表扫描告警的行数阈值，0 表示关闭
+	fullScanThreshold atomic.Int64
+	// explainedSql 已执行过 EXPLAIN 的sql文本，相同sql只检查一次
+	explainedSql   sync.Map
+	explainedCount atomic.Int64
+)
+
+// WarnFullScan 开启全表扫描告警：调试模式下首次执行某条查询前先执行 EXPLAIN（按sql文本缓存，事务内的查询不检查），
+// 存在估算行数不小于 rowThreshold 的全表扫描时输出告警日志
+func WarnFullScan(rowThreshold int64) {
+	fullScanThreshold.Store(rowThreshold)
+	explainedSql.Clear()
+	explainedCount.Store(0)
+}
+
+// UnWarnFullScan 关闭全表扫描告警
+func UnWarnFullScan() {
+	fullScanThreshold.Store(0)
+}
+
+// Explain 获取查询的执行计划（不实际执行查询）
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - *base.QueryPlan: 执行计划
+//   - error: 查询失败的错误信息
+func (e *Executor[T]) Explain(ctx context.Context) (*base.QueryPlan, error) {
+	return e.explain(ctx, false)
+}
+
+// ExplainAnalyze 实际执行查询并获取执行计划（EXPLAIN ANALYZE），包含实际行数
+//
+// 参数:
+//   - ctx: 上下文
+//
+// 返回:
+//   - *base.QueryPlan: 执行计划
+//   - error: 查询失败的错误信息
+func (e *Executor[T]) ExplainAnalyze(ctx context.Context) (*base.QueryPlan, error) {
+	return e.explain(ctx, true)
+}
+
+func (e *Executor[T]) explain(ctx context.Context, analyze bool) (*base.QueryPlan, error) {
+	if _, ok := e.builder.(base.SelectBuilder); !ok {
+		return nil, base.ErrorExecutorNotSupportSelect
+	}
+	if err := e.prepare(); err != nil {
+		return nil, err
+	}
+	s, args := e.builder.Build()
+	return e.explainSql(ctx, analyze, s, args...)
+}
+
+func (e *Executor[T]) explainSql(ctx context.Context, analyze bool, s string, args ...any) (*base.QueryPlan, error) {
+	dialect := e.getDialect()
+	rows, err := e.conn().QueryContext(ctx, base.ExplainSql(dialect, s, analyze), args...)
+	if err != nil {
+		return nil, base.TranslateError(err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			logger.Error("close rows failed: %w", err)
+		}
+	}(rows)
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, base.TranslateError(err)
+	}
+	return base.ParsePlan(dialect, strings.Join(lines, "\n"), analyze)
+}
+
+// warnFullScan 调试模式下检查查询是否存在超过阈值的全表扫描。
+// 事务内不执行 EXPLAIN，避免额外语句影响事务（如 PostgreSQL 中 EXPLAIN 失败会中止事务）
+func (e *Executor[T]) warnFullScan(s string, args ...any) {
+	threshold := fullScanThreshold.Load()
+	if threshold <= 0 || e.tx != nil || !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(s)), "SELECT") {
+		return
+	}
+	if _, explained := explainedSql.LoadOrStore(s, struct{}{}); explained {
+		return
+	}
+	if explainedCount.Add(1) > explainedCacheSize {
+		explainedSql.Clear()
+		explainedCount.Store(0)
+	}
+	plan, err := e.explainSql(context.Background(), false, s, args...)
+	if err != nil {
+		logger.Warn("explain query failed: %v", err)
+		return
+	}
+	for _, node := range plan.Nodes {
+		if node.FullScan && node.EstimatedRows >= threshold {
+			logger.Warn("full table scan on %s, estimated rows: %d\nSQL: %s", node.Table, node.EstimatedRows, s)
+		}
+	}
+}
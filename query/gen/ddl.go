@@ -0,0 +1,292 @@
+package gen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Cooooing/cutil/query/base"
+)
+
+// Table 表结构
+type Table struct {
+	Name       string
+	Comment    string
+	Columns    []*Column
+	PrimaryKey []string
+}
+
+// Column 列结构
+type Column struct {
+	Name       string
+	Type       string // 小写的完整类型，如 varchar(64)、bigint unsigned、timestamp with time zone
+	Nullable   bool
+	PrimaryKey bool
+	Comment    string
+}
+
+var (
+	createTableRegexp   = regexp.MustCompile(`(?is)^CREATE\s+(?:TEMPORARY\s+|UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s*\(`)
+	tableCommentRegexp  = regexp.MustCompile(`(?is)COMMENT\s*=?\s*'((?:[^']|'')*)'`)
+	commentOnRegexp     = regexp.MustCompile(`(?is)^COMMENT\s+ON\s+(TABLE|COLUMN)\s+(\S+)\s+IS\s+'((?:[^']|'')*)'`)
+	primaryKeyRegexp    = regexp.MustCompile(`(?is)PRIMARY\s+KEY\s*\(([^)]*)\)`)
+	columnCommentRegexp = regexp.MustCompile(`(?is)\sCOMMENT\s+'((?:[^']|'')*)'`)
+)
+
+// 类型之后出现的列约束关键字
+var columnKeywords = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "AUTO_INCREMENT": true, "COMMENT": true,
+	"UNIQUE": true, "REFERENCES": true, "CHECK": true, "GENERATED": true, "COLLATE": true, "CHARACTER": true,
+	"CHARSET": true, "ON": true, "CONSTRAINT": true, "AS": true, "IDENTITY": true, "VIRTUAL": true, "STORED": true,
+}
+
+// 表级约束关键字
+var constraintKeywords = []string{"PRIMARY", "KEY", "INDEX", "UNIQUE", "CONSTRAINT", "FOREIGN", "CHECK", "FULLTEXT", "SPATIAL", "EXCLUDE"}
+
+// ParseDDL 解析 CREATE TABLE 语句（支持 MySQL 与 PostgreSQL），同时识别 PostgreSQL 的 COMMENT ON 语句
+//
+// 参数:
+//   - dialect: 数据库方言，MySQL 额外识别 # 注释
+//   - ddl: DDL 内容，可包含多条语句
+//
+// 返回:
+//   - []*Table: 表结构
+//   - error: 解析失败的错误信息
+func ParseDDL(dialect base.Dialect, ddl string) ([]*Table, error) {
+	var tables []*Table
+	tableMap := make(map[string]*Table)
+
+	for _, stmt := range splitStatements(stripComments(ddl, dialect == base.DialectMySQL)) {
+		if match := commentOnRegexp.FindStringSubmatch(stmt); match != nil {
+			applyCommentOn(tableMap, strings.ToUpper(match[1]), match[2], unescape(match[3]))
+			continue
+		}
+		loc := createTableRegexp.FindStringSubmatchIndex(stmt)
+		if loc == nil {
+			continue
+		}
+		table := &Table{Name: lastIdentifier(stmt[loc[2]:loc[3]])}
+		end := matchParen(stmt, loc[1]-1)
+		if end < 0 {
+			return nil, fmt.Errorf("table %s: unbalanced parentheses", table.Name)
+		}
+		if match := tableCommentRegexp.FindStringSubmatch(stmt[end+1:]); match != nil {
+			table.Comment = unescape(match[1])
+		}
+		for _, def := range splitTopLevel(stmt[loc[1]:end], ',') {
+			def = strings.TrimSpace(def)
+			if def == "" {
+				continue
+			}
+			if isConstraint(def) {
+				if match := primaryKeyRegexp.FindStringSubmatch(def); match != nil {
+					for _, col := range strings.Split(match[1], ",") {
+						table.PrimaryKey = append(table.PrimaryKey, unquote(strings.Fields(col)[0]))
+					}
+				}
+				continue
+			}
+			column, err := parseColumn(def)
+			if err != nil {
+				return nil, fmt.Errorf("table %s: %w", table.Name, err)
+			}
+			if column.PrimaryKey {
+				table.PrimaryKey = append(table.PrimaryKey, column.Name)
+			}
+			table.Columns = append(table.Columns, column)
+		}
+		for _, pk := range table.PrimaryKey {
+			for _, column := range table.Columns {
+				if strings.EqualFold(column.Name, pk) {
+					column.PrimaryKey = true
+					column.Nullable = false
+				}
+			}
+		}
+		tables = append(tables, table)
+		tableMap[strings.ToLower(table.Name)] = table
+	}
+	return tables, nil
+}
+
+// parseColumn 解析列定义
+func parseColumn(def string) (*Column, error) {
+	fields := strings.Fields(def)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid column definition: %s", def)
+	}
+	column := &Column{Name: unquote(fields[0]), Nullable: true}
+
+	// 类型由列名之后、第一个约束关键字之前的部分组成，括号内可能含空格
+	rest := strings.TrimSpace(def[len(fields[0]):])
+	var typeParts []string
+	for _, part := range splitTopLevel(rest, ' ') {
+		if part == "" {
+			continue
+		}
+		// PostgreSQL 的 character varying 以 CHARACTER 开头，仅在类型之后才视为关键字
+		if len(typeParts) > 0 && columnKeywords[strings.ToUpper(part)] {
+			break
+		}
+		typeParts = append(typeParts, part)
+	}
+	column.Type = strings.ToLower(strings.Join(typeParts, " "))
+
+	upper := " " + strings.ToUpper(rest) + " "
+	if strings.Contains(upper, " NOT NULL ") {
+		column.Nullable = false
+	}
+	if strings.Contains(upper, " PRIMARY KEY ") {
+		column.PrimaryKey = true
+		column.Nullable = false
+	}
+	if match := columnCommentRegexp.FindStringSubmatch(" " + rest); match != nil {
+		column.Comment = unescape(match[1])
+	}
+	return column, nil
+}
+
+// applyCommentOn 将 COMMENT ON 语句的注释应用到已解析的表
+func applyCommentOn(tables map[string]*Table, kind string, target string, comment string) {
+	parts := strings.Split(target, ".")
+	for i := range parts {
+		parts[i] = unquote(parts[i])
+	}
+	if kind == "TABLE" {
+		if table, ok := tables[strings.ToLower(parts[len(parts)-1])]; ok {
+			table.Comment = comment
+		}
+		return
+	}
+	if len(parts) < 2 {
+		return
+	}
+	table, ok := tables[strings.ToLower(parts[len(parts)-2])]
+	if !ok {
+		return
+	}
+	for _, column := range table.Columns {
+		if strings.EqualFold(column.Name, parts[len(parts)-1]) {
+			column.Comment = comment
+		}
+	}
+}
+
+func isConstraint(def string) bool {
+	first := strings.ToUpper(strings.Fields(def)[0])
+	for _, keyword := range constraintKeywords {
+		if first == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// stripComments 去除 -- 与 /* */ 注释，hashComment 为 true 时同时去除 # 注释（MySQL）
+func stripComments(s string, hashComment bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			end := skipQuoted(s, i)
+			sb.WriteString(s[i:end])
+			i = end - 1
+		case strings.HasPrefix(s[i:], "--") || (hashComment && s[i] == '#'):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			sb.WriteByte('\n')
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return sb.String()
+			}
+			i += end + 3
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// splitStatements 按分号拆分语句
+func splitStatements(s string) []string {
+	var stmts []string
+	for _, stmt := range splitTopLevel(s, ';') {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// splitTopLevel 按分隔符拆分，忽略括号与引号内的分隔符
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(s, i) - 1
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && (c == sep || (sep == ' ' && (c == '\t' || c == '\n' || c == '\r'))):
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// matchParen 返回与 start 处左括号匹配的右括号位置
+func matchParen(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '"', '`':
+			i = skipQuoted(s, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func skipQuoted(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		if s[i] == '\\' && quote == '\'' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// lastIdentifier 返回 schema.table 中的表名
+func lastIdentifier(s string) string {
+	parts := strings.Split(s, ".")
+	return unquote(parts[len(parts)-1])
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), "`\"[]")
+}
+
+func unescape(s string) string {
+	return strings.ReplaceAll(s, "''", "'")
+}
@@ -0,0 +1,172 @@
+package gen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/Cooooing/cutil/query/base"
+)
+
+const mysqlDDL = `
+-- 用户表
+CREATE TABLE IF NOT EXISTS ` + "`test`.`user_info`" + ` (
+  ` + "`id`" + ` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  ` + "`name`" + ` varchar(64) CHARACTER SET utf8mb4 NOT NULL DEFAULT '' COMMENT '姓名; 昵称',
+  ` + "`age`" + ` int DEFAULT NULL,
+  ` + "`enabled`" + ` tinyint(1) NOT NULL DEFAULT 1,
+  ` + "`balance`" + ` decimal(10, 2) DEFAULT NULL,
+  ` + "`created_at`" + ` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (` + "`id`" + `),
+  UNIQUE KEY ` + "`uk_name`" + ` (` + "`name`" + `)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户信息';
+`
+
+const postgresDDL = `
+CREATE TABLE public.post (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES user_info (id),
+    title character varying(128) NOT NULL,
+    tags text[],
+    scores int[],
+    flags int NOT NULL CHECK (flags # 1 >= 0), published_at timestamp with time zone,
+    CONSTRAINT post_title_key UNIQUE (title)
+);
+COMMENT ON TABLE public.post IS '文章';
+COMMENT ON COLUMN public.post.title IS '标题';
+`
+
+func TestParseDDL(t *testing.T) {
+	tables, err := ParseDDL(base.DialectMySQL, mysqlDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	postgresTables, err := ParseDDL(base.DialectPostgres, postgresDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables = append(tables, postgresTables...)
+	if len(tables) != 2 {
+		t.Fatalf("ParseDDL() got %d tables, want 2", len(tables))
+	}
+
+	user := tables[0]
+	if user.Name != "user_info" || user.Comment != "用户信息" || len(user.Columns) != 6 {
+		t.Errorf("ParseDDL() user = %+v", user)
+	}
+	if !user.Columns[0].PrimaryKey || user.Columns[0].Type != "bigint unsigned" {
+		t.Errorf("ParseDDL() user.id = %+v", user.Columns[0])
+	}
+	if user.Columns[1].Type != "varchar(64)" || user.Columns[1].Nullable || user.Columns[1].Comment != "姓名; 昵称" {
+		t.Errorf("ParseDDL() user.name = %+v", user.Columns[1])
+	}
+
+	post := tables[1]
+	if post.Name != "post" || post.Comment != "文章" || post.Columns[2].Comment != "标题" {
+		t.Errorf("ParseDDL() post = %+v", post)
+	}
+	if post.Columns[2].Type != "character varying(128)" {
+		t.Errorf("ParseDDL() post.title = %+v", post.Columns[2])
+	}
+	// PostgreSQL 中 # 为按位异或运算符，不是注释
+	if len(post.Columns) != 7 || post.Columns[6].Name != "published_at" {
+		t.Errorf("ParseDDL() post columns = %+v", post.Columns)
+	}
+}
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		dialect base.Dialect
+		column  Column
+		want    string
+	}{
+		{base.DialectMySQL, Column{Type: "bigint unsigned"}, "uint64"},
+		{base.DialectMySQL, Column{Type: "int", Nullable: true}, "*int32"},
+		{base.DialectMySQL, Column{Type: "tinyint(1)"}, "bool"},
+		{base.DialectMySQL, Column{Type: "decimal(10, 2)", Nullable: true}, "*string"},
+		{base.DialectMySQL, Column{Type: "datetime"}, "time.Time"},
+		{base.DialectMySQL, Column{Type: "blob", Nullable: true}, "[]byte"},
+		{base.DialectPostgres, Column{Type: "timestamp with time zone", Nullable: true}, "*time.Time"},
+		{base.DialectPostgres, Column{Type: "character varying(128)"}, "string"},
+		{base.DialectPostgres, Column{Type: "text[]", Nullable: true}, "pq.StringArray"},
+		{base.DialectPostgres, Column{Type: "bigint[]"}, "pq.Int64Array"},
+		{base.DialectPostgres, Column{Type: "timestamp[]"}, "pq.StringArray"},
+		{base.DialectPostgres, Column{Type: "double precision"}, "float64"},
+	}
+	for _, tt := range tests {
+		if got := GoType(tt.dialect, &tt.column); got != tt.want {
+			t.Errorf("GoType(%s) = %s, want %s", tt.column.Type, got, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tables, err := ParseDDL(base.DialectMySQL, mysqlDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate("model", base.DialectMySQL, tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(src)
+	for _, want := range []string{
+		"type UserInfo struct",
+		"`corm:\"column:id;primaryKey;comment:主键\" json:\"id\"`",
+		"`corm:\"column:name;comment:姓名, 昵称\" json:\"name\"`",
+		"UserInfoColumnCreatedAt UserInfoColumn = \"created_at\"",
+		"var UserInfoTable = struct",
+		"\"time\"",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Generate() missing %q in:\n%s", want, code)
+		}
+	}
+}
+
+func TestGeneratePostgresArray(t *testing.T) {
+	tables, err := ParseDDL(base.DialectPostgres, postgresDDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate("model", base.DialectPostgres, tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(src)
+	for _, want := range []string{"\"github.com/lib/pq\"", "Tags        pq.StringArray", "Scores      pq.Int32Array"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Generate() missing %q in:\n%s", want, code)
+		}
+	}
+}
+
+func TestGenerateTableNameColumn(t *testing.T) {
+	ddl := "CREATE TABLE audit_log (id bigint NOT NULL, table_name varchar(64) NOT NULL, PRIMARY KEY (id));"
+	tables, err := ParseDDL(base.DialectMySQL, ddl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate("model", base.DialectMySQL, tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "AuditLogColumnTableNameField AuditLogColumn = \"table_name\"") {
+		t.Errorf("Generate() missing renamed field in:\n%s", src)
+	}
+
+	// 生成的代码需要通过类型检查，字段与方法不能同名
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "model.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("model", fset, []*ast.File{file}, nil); err != nil {
+		t.Errorf("type check generated code failed: %v\n%s", err, src)
+	}
+}
@@ -0,0 +1,256 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/Cooooing/cutil/base/str"
+	"github.com/Cooooing/cutil/query/base"
+)
+
+var typeNameRegexp = regexp.MustCompile(`^([a-z0-9_ ]+?)\s*(?:\(([^)]*)\))?(\s+unsigned)?(?:\s+zerofill)?\s*(\[\])?$`)
+
+// GoType 将 sql 类型映射为 go 类型，可为空的列映射为指针（[]byte 与数组除外），PostgreSQL 数组映射为 pq 的数组类型
+//
+// 参数:
+//   - dialect: 数据库方言
+//   - column: 列结构
+//
+// 返回:
+//   - string: go 类型
+func GoType(dialect base.Dialect, column *Column) string {
+	goType := baseGoType(dialect, column.Type)
+	if column.Nullable && !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "pq.") {
+		return "*" + goType
+	}
+	return goType
+}
+
+func baseGoType(dialect base.Dialect, sqlType string) string {
+	match := typeNameRegexp.FindStringSubmatch(strings.TrimSpace(sqlType))
+	if match == nil {
+		return "string"
+	}
+	name, size, unsigned, array := strings.TrimSpace(match[1]), match[2], match[3] != "", match[4] != ""
+	// 去除时区等修饰，如 timestamp with time zone
+	if fields := strings.Fields(name); len(fields) > 0 && (fields[0] == "timestamp" || fields[0] == "time") {
+		name = fields[0]
+	}
+
+	var goType string
+	switch name {
+	case "bool", "boolean":
+		goType = "bool"
+	case "tinyint":
+		if size == "1" && dialect == base.DialectMySQL {
+			goType = "bool"
+		} else {
+			goType = "int8"
+		}
+	case "smallint", "int2", "smallserial", "serial2":
+		goType = "int16"
+	case "mediumint", "int", "integer", "int4", "serial", "serial4":
+		goType = "int32"
+	case "bigint", "int8", "bigserial", "serial8":
+		goType = "int64"
+	case "float", "real", "float4":
+		goType = "float32"
+	case "double", "double precision", "float8":
+		goType = "float64"
+	case "decimal", "numeric", "money":
+		// 定点数使用 string 避免精度丢失
+		goType = "string"
+	case "date", "datetime", "timestamp", "timestamptz":
+		goType = "time.Time"
+	case "binary", "varbinary", "blob", "tinyblob", "mediumblob", "longblob", "bytea", "bit":
+		goType = "[]byte"
+	default:
+		goType = "string"
+	}
+	if unsigned && strings.HasPrefix(goType, "int") {
+		goType = "u" + goType
+	}
+	if array {
+		return arrayGoType(goType)
+	}
+	return goType
+}
+
+// arrayGoType 返回 PostgreSQL 数组对应的 pq 数组类型，没有对应类型的元素按文本读取
+func arrayGoType(elemType string) string {
+	switch elemType {
+	case "bool":
+		return "pq.BoolArray"
+	case "int16", "int32":
+		return "pq.Int32Array"
+	case "int64":
+		return "pq.Int64Array"
+	case "float32":
+		return "pq.Float32Array"
+	case "float64":
+		return "pq.Float64Array"
+	case "[]byte":
+		return "pq.ByteaArray"
+	}
+	return "pq.StringArray"
+}
+
+// tableNameMethod 生成的模型返回表名的方法名
+const tableNameMethod = "TableName"
+
+type fieldData struct {
+	Name    string
+	Type    string
+	Tag     string
+	Column  string
+	Comment string
+}
+
+type modelData struct {
+	Name       string
+	Table      string
+	Comment    string
+	Fields     []fieldData
+	PrimaryKey []string
+}
+
+var modelTemplate = template.Must(template.New("model").Parse(`// Code generated by cutil-gen. DO NOT EDIT.
+
+package {{.Package}}
+{{if .Imports}}
+import (
+{{range .Imports}}	"{{.}}"
+{{end}})
+{{end}}
+{{range .Models}}
+// {{.Name}} {{if .Comment}}{{.Comment}}{{else}}{{.Table}}{{end}}
+type {{.Name}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}} ` + "`{{.Tag}}`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{end}}}
+
+// TableName 返回表名
+func ({{.Name}}) TableName() string {
+	return "{{.Table}}"
+}
+
+// {{.Name}}Column {{.Table}} 表列名
+type {{.Name}}Column string
+
+const (
+{{$model := .}}{{range .Fields}}	{{$model.Name}}Column{{.Name}} {{$model.Name}}Column = "{{.Column}}"
+{{end}})
+
+// String 返回列名
+func (c {{.Name}}Column) String() string {
+	return string(c)
+}
+
+// Alias 返回带表别名的列名，如 u.id
+func (c {{.Name}}Column) Alias(alias string) string {
+	return alias + "." + string(c)
+}
+
+// {{.Name}}Table {{.Table}} 表描述
+var {{.Name}}Table = struct {
+	Name       string
+	Columns    []{{.Name}}Column
+	PrimaryKey []{{.Name}}Column
+}{
+	Name: "{{.Table}}",
+	Columns: []{{.Name}}Column{
+{{range .Fields}}		{{$model.Name}}Column{{.Name}},
+{{end}}	},
+	PrimaryKey: []{{.Name}}Column{
+{{range .PrimaryKey}}		{{$model.Name}}Column{{.}},
+{{end}}	},
+}
+{{end}}`))
+
+// Generate 根据表结构生成 go 代码
+//
+// 参数:
+//   - pkg: 包名
+//   - dialect: 数据库方言
+//   - tables: 表结构
+//
+// 返回:
+//   - []byte: 格式化后的 go 代码
+//   - error: 生成失败的错误信息
+func Generate(pkg string, dialect base.Dialect, tables []*Table) ([]byte, error) {
+	var (
+		models   []modelData
+		needTime bool
+		needPq   bool
+	)
+	for _, table := range tables {
+		model := modelData{
+			Name:    str.ToUpperCamelCase(table.Name),
+			Table:   table.Name,
+			Comment: sanitizeComment(table.Comment),
+		}
+		for _, column := range table.Columns {
+			field := fieldData{
+				Name:    str.ToUpperCamelCase(column.Name),
+				Type:    GoType(dialect, column),
+				Column:  column.Name,
+				Comment: sanitizeComment(column.Comment),
+			}
+			// 字段不能与生成的 TableName 方法同名（如审计表的 table_name 列），列名由标签映射，重命名字段不影响读写
+			if field.Name == tableNameMethod {
+				field.Name += "Field"
+			}
+			field.Tag = fmt.Sprintf(`%s:"%s" json:"%s"`, base.FieldTag, cormTag(column), column.Name)
+			needTime = needTime || strings.Contains(field.Type, "time.Time")
+			needPq = needPq || strings.HasPrefix(field.Type, "pq.")
+			if column.PrimaryKey {
+				model.PrimaryKey = append(model.PrimaryKey, field.Name)
+			}
+			model.Fields = append(model.Fields, field)
+		}
+		models = append(models, model)
+	}
+
+	data := map[string]any{
+		"Package": pkg,
+		"Models":  models,
+	}
+	var imports []string
+	if needTime {
+		imports = append(imports, "time")
+	}
+	if needPq {
+		imports = append(imports, "github.com/lib/pq")
+	}
+	data["Imports"] = imports
+	var buf bytes.Buffer
+	if err := modelTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("execute template failed: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format source failed: %w", err)
+	}
+	return src, nil
+}
+
+// cormTag 生成 corm 标签内容
+func cormTag(column *Column) string {
+	parts := []string{base.FieldTagColumn + ":" + column.Name}
+	if column.PrimaryKey {
+		parts = append(parts, base.FieldTagPrimaryKey)
+	}
+	if comment := sanitizeComment(column.Comment); comment != "" {
+		// 标签中不能包含分号与双引号
+		comment = strings.NewReplacer(";", ",", `"`, "'", "`", "'").Replace(comment)
+		parts = append(parts, base.FieldTagComment+":"+comment)
+	}
+	return strings.Join(parts, ";")
+}
+
+func sanitizeComment(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,27 @@
+package sql
+
+import (
+	"github.com/Cooooing/cutil/query/base"
+)
+
+// Named 将 :name / @name 命名参数sql改写为全局默认方言的位置参数sql，结果可直接用于 PageQuery* 等函数
+//
+//	query, args, err := Named(`select * from user where age > :age and id in (:ids)`, map[string]any{"age": 18, "ids": []int{1, 2}})
+//	res, err := PageQueryForMap(db, page, query, args...)
+//
+// 参数:
+//   - query: 包含命名参数的sql
+//   - param: 参数来源，map[string]any 或结构体（按 corm column、json 标签及字段名匹配）
+//
+// 返回:
+//   - string: 改写后的sql
+//   - []any: 位置参数
+//   - error: 参数缺失或类型不支持的错误信息
+func Named(query string, param any) (string, []any, error) {
+	return base.BindNamed(base.GetDialect(), query, param)
+}
+
+// Named 将命名参数sql改写为连接方言的位置参数sql，用法同包级函数 Named
+func (db *DB) Named(query string, param any) (string, []any, error) {
+	return base.BindNamed(db.Dialect(), query, param)
+}
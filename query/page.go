@@ -38,10 +38,11 @@ func (p *PageReq) GetSize() int {
 // ---------------- PageResp ----------------
 
 type PageResp[T any] struct {
-	Page  int  `json:"page"`
-	Size  int  `json:"size"`
-	Total int  `json:"total"`
-	List  []*T `json:"list"`
+	Page        int  `json:"page"`
+	Size        int  `json:"size"`
+	Total       int  `json:"total"`
+	TotalCapped bool `json:"totalCapped,omitempty"` // 总数达到计数上限，实际总数可能更多
+	List        []*T `json:"list"`
 }
 
 func (p *PageResp[T]) SetList(data []*T) {
@@ -52,6 +53,10 @@ func (p *PageResp[T]) SetTotal(total int) {
 	p.Total = total
 }
 
+func (p *PageResp[T]) SetTotalCapped(capped bool) {
+	p.TotalCapped = capped
+}
+
 func (p *PageResp[T]) SetPageReq(pageReq base.PageReqInterface) {
 	p.Page = pageReq.GetPage()
 	p.Size = pageReq.GetSize()
@@ -0,0 +1,45 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Cooooing/cutil/query/base"
+)
+
+// rebinder 执行前将 ? 占位符改写为方言对应的位置参数
+type rebinder struct {
+	conn    base.Queryer
+	dialect base.Dialect
+}
+
+// rebind 返回按方言改写占位符的连接，不需要改写的方言直接返回原连接
+//
+// 参数:
+//   - conn: 数据库连接或事务
+//   - dialect: 数据库方言
+//
+// 返回:
+//   - base.Queryer: 执行时改写占位符的连接
+func rebind(conn base.Queryer, dialect base.Dialect) base.Queryer {
+	if dialect != base.DialectPostgres {
+		return conn
+	}
+	return &rebinder{conn: conn, dialect: dialect}
+}
+
+func (r *rebinder) Exec(query string, args ...any) (sql.Result, error) {
+	return r.conn.Exec(base.Rebind(r.dialect, query), args...)
+}
+
+func (r *rebinder) Query(query string, args ...any) (*sql.Rows, error) {
+	return r.conn.Query(base.Rebind(r.dialect, query), args...)
+}
+
+func (r *rebinder) QueryRow(query string, args ...any) *sql.Row {
+	return r.conn.QueryRow(base.Rebind(r.dialect, query), args...)
+}
+
+func (r *rebinder) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.conn.QueryContext(ctx, base.Rebind(r.dialect, query), args...)
+}
@@ -0,0 +1,469 @@
+package sql
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Cooooing/cutil/query/base"
+)
+
+var (
+	// ErrShardKeyMissing 插入分表时插入值中没有分片键
+	ErrShardKeyMissing = errors.New("shard key not found in insert values")
+	// ErrShardCrossRows 一次插入的多行数据属于不同分片
+	ErrShardCrossRows = errors.New("insert rows belong to multiple shards")
+)
+
+// ShardRule 逻辑表的分表规则
+type ShardRule struct {
+	Table    string        // 逻辑表名
+	Column   string        // 分片键
+	Strategy ShardStrategy // 分片策略
+	Format   string        // 物理表名格式，参数为逻辑表名与分片序号，默认 %s_%02d（如 orders_07）
+	DBs      []*sql.DB     // 分库连接，分片 i 使用 DBs[i%len(DBs)]，为空时使用路由的默认连接
+}
+
+// ShardTarget 路由结果
+type ShardTarget struct {
+	Shard int     // 分片序号
+	Table string  // 物理表名，未分表时为空
+	DB    *sql.DB // 执行的连接
+}
+
+// ShardRouter 分表路由，根据构建器条件或插入值中的分片键将逻辑表映射为物理表
+type ShardRouter struct {
+	db    *sql.DB
+	rules map[string]*ShardRule
+}
+
+// NewShardRouter 创建分表路由
+//
+// 参数:
+//   - db: 默认连接，未分表的表及未指定分库的分片使用该连接
+//
+// 返回:
+//   - *ShardRouter: 分表路由
+func NewShardRouter(db *sql.DB) *ShardRouter {
+	return &ShardRouter{db: db, rules: make(map[string]*ShardRule)}
+}
+
+// Register 注册分表规则
+func (r *ShardRouter) Register(rule ShardRule) *ShardRouter {
+	if rule.Table == "" || rule.Column == "" || rule.Strategy == nil {
+		panic("shard rule must have table, column and strategy")
+	}
+	if rule.Format == "" {
+		rule.Format = "%s_%02d"
+	}
+	r.rules[rule.Table] = &rule
+	return r
+}
+
+// Route 路由构建器。条件中包含分片键（= 或 IN）时路由到对应分片，否则查询、更新、删除路由到全部分片；
+// 插入必须包含分片键且所有行属于同一分片
+//
+// 参数:
+//   - builder: 构建器
+//
+// 返回:
+//   - []ShardTarget: 路由结果，未分表的表返回默认连接
+//   - error: 路由失败的错误信息
+func (r *ShardRouter) Route(builder base.Builder) ([]ShardTarget, error) {
+	sb, ok := builder.(base.ShardBuilder)
+	if !ok {
+		return []ShardTarget{{DB: r.db}}, nil
+	}
+	rule, ok := r.rules[sb.LogicalTable()]
+	if !ok {
+		return []ShardTarget{{DB: r.db}}, nil
+	}
+
+	values, found := sb.ShardValues(rule.Column)
+	_, insert := builder.(base.InsertBuilder)
+	if !found && insert {
+		return nil, ErrShardKeyMissing
+	}
+
+	var shards []int
+	if found {
+		seen := make(map[int]bool)
+		for _, value := range values {
+			shard, err := rule.Strategy.Shard(value)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[shard] {
+				seen[shard] = true
+				shards = append(shards, shard)
+			}
+		}
+		sort.Ints(shards)
+		if insert && len(shards) > 1 {
+			return nil, ErrShardCrossRows
+		}
+	} else {
+		for shard := 0; shard < rule.Strategy.Count(); shard++ {
+			shards = append(shards, shard)
+		}
+	}
+
+	targets := make([]ShardTarget, len(shards))
+	for i, shard := range shards {
+		db := r.db
+		if len(rule.DBs) > 0 {
+			db = rule.DBs[shard%len(rule.DBs)]
+		}
+		targets[i] = ShardTarget{Shard: shard, Table: fmt.Sprintf(rule.Format, rule.Table, shard), DB: db}
+	}
+	return targets, nil
+}
+
+// ShardExecutor 分表执行器，使用逻辑表名构建的语句经路由后在物理表上执行。
+// 缺少分片键的查询并发查询全部分片，合并结果后按 ORDER BY 排序并应用 LIMIT/OFFSET；
+// 跨分片的聚合（GROUP BY、DISTINCT）结果不会合并。缺少分片键的更新与删除依次在各分片执行，不保证原子性
+type ShardExecutor[T any] struct {
+	router  *ShardRouter
+	builder base.Builder
+	ctx     context.Context
+	debug   bool
+	dialect base.Dialect // 数据库方言，未指定时使用全局默认方言
+}
+
+func WithShardExecutor[T any](router *ShardRouter, builder base.Builder) *ShardExecutor[T] {
+	return &ShardExecutor[T]{
+		router:  router,
+		builder: cloneBuilder(builder),
+	}
+}
+
+// WithContext 设置执行上下文
+func (e *ShardExecutor[T]) WithContext(ctx context.Context) *ShardExecutor[T] {
+	e.ctx = ctx
+	return e
+}
+
+func (e *ShardExecutor[T]) Debug() *ShardExecutor[T] {
+	e.debug = true
+	return e
+}
+
+// Dialect 指定执行器使用的数据库方言
+func (e *ShardExecutor[T]) Dialect(dialect base.Dialect) *ShardExecutor[T] {
+	e.dialect = dialect
+	return e
+}
+
+func (e *ShardExecutor[T]) getDialect() base.Dialect {
+	if e.dialect != "" {
+		return e.dialect
+	}
+	return base.GetDialect()
+}
+
+// executor 返回在目标分片上执行的执行器
+func (e *ShardExecutor[T]) executor(target ShardTarget) *Executor[T] {
+	if sb, ok := e.builder.(base.ShardBuilder); ok {
+		sb.PhysicalTable(target.Table)
+	}
+	executor := WithExecutor[T](target.DB, e.builder).WithContext(e.ctx).Dialect(e.dialect)
+	if e.debug {
+		executor.Debug()
+	}
+	return executor
+}
+
+func (e *ShardExecutor[T]) Exec() (sql.Result, error) {
+	targets, err := e.router.Route(e.builder)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 1 {
+		return e.executor(targets[0]).Exec()
+	}
+	var result sumResult
+	for _, target := range targets {
+		r, err := e.executor(target).Exec()
+		if err != nil {
+			return result, fmt.Errorf("exec on %s failed: %w", target.Table, err)
+		}
+		affected, err := r.RowsAffected()
+		if err != nil {
+			return result, err
+		}
+		result += sumResult(affected)
+	}
+	return result, nil
+}
+
+func (e *ShardExecutor[T]) First() (*T, error) {
+	sb, ok := e.builder.(base.SelectBuilder)
+	if !ok {
+		return nil, base.ErrorExecutorNotSupportSelect
+	}
+	limit := sb.GetLimit()
+	sb.Limit(1)
+	defer sb.Limit(limit)
+	list, err := e.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, base.ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (e *ShardExecutor[T]) List() ([]*T, error) {
+	sb, ok := e.builder.(base.SelectBuilder)
+	if !ok {
+		return nil, base.ErrorExecutorNotSupportSelect
+	}
+	targets, err := e.router.Route(e.builder)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 1 {
+		return e.executor(targets[0]).List()
+	}
+
+	orders, err := parseShardOrders[T](sb.GetOrderBy())
+	if err != nil {
+		return nil, err
+	}
+	// 各分片查询前 offset+limit 行，合并排序后再截取
+	limit, offset := sb.GetLimit(), sb.GetOffset()
+	if limit >= 0 {
+		sb.Limit(limit + max(offset, 0))
+	}
+	sb.Offset(-1)
+	defer func() {
+		sb.Limit(limit)
+		sb.Offset(offset)
+	}()
+
+	statements, err := e.statements(targets, base.SelectBuilder.Build)
+	if err != nil {
+		return nil, err
+	}
+	results := make([][]*T, len(targets))
+	err = fanOut(targets, func(i int, target ShardTarget) (err error) {
+		results[i], err = base.Raws2Struct[T](rebind(target.DB, e.getDialect()), statements[i].Sql, statements[i].Args...)
+		return base.TranslateError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*T
+	for _, result := range results {
+		list = append(list, result...)
+	}
+	if len(orders) > 0 {
+		sort.SliceStable(list, func(i, j int) bool {
+			return lessByOrders(reflect.ValueOf(list[i]).Elem(), reflect.ValueOf(list[j]).Elem(), orders)
+		})
+	}
+	if offset > 0 {
+		list = list[min(offset, len(list)):]
+	}
+	if limit >= 0 && limit < len(list) {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (e *ShardExecutor[T]) Count() (int, error) {
+	if _, ok := e.builder.(base.SelectBuilder); !ok {
+		return 0, base.ErrorExecutorNotSupportSelect
+	}
+	targets, err := e.router.Route(e.builder)
+	if err != nil {
+		return 0, err
+	}
+	if len(targets) == 1 {
+		return e.executor(targets[0]).Count()
+	}
+	statements, err := e.statements(targets, func(sb base.SelectBuilder) (string, []any) { return sb.BuildCount(-1) })
+	if err != nil {
+		return 0, err
+	}
+	counts := make([]int, len(targets))
+	err = fanOut(targets, func(i int, target ShardTarget) error {
+		conn := rebind(target.DB, e.getDialect())
+		return base.TranslateError(conn.QueryRow(statements[i].Sql, statements[i].Args...).Scan(&counts[i]))
+	})
+	if err != nil {
+		return 0, err
+	}
+	var total int
+	for _, count := range counts {
+		total += count
+	}
+	return total, nil
+}
+
+func (e *ShardExecutor[T]) Page(page base.PageReqInterface) (base.PageRespInterface[T], error) {
+	sb, ok := e.builder.(base.SelectBuilder)
+	if !ok {
+		return nil, base.ErrorExecutorNotSupportSelect
+	}
+	if page == nil {
+		page = getDefaultPageReq()
+	}
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	pageResp := getDefaultPageResp[T]()
+	pageResp.SetPageReq(page)
+
+	total, err := e.Count()
+	if err != nil {
+		return nil, err
+	}
+	pageResp.SetTotal(total)
+
+	limit, offset := sb.GetLimit(), sb.GetOffset()
+	sb.Limit(page.GetSize()).Offset((page.GetPage() - 1) * page.GetSize())
+	defer func() {
+		sb.Limit(limit)
+		sb.Offset(offset)
+	}()
+	list, err := e.List()
+	if err != nil {
+		return nil, err
+	}
+	pageResp.SetList(list)
+	return pageResp, nil
+}
+
+// statements 依次为各分片注入范围限定并构建语句
+func (e *ShardExecutor[T]) statements(targets []ShardTarget, build func(sb base.SelectBuilder) (string, []any)) ([]base.Statement, error) {
+	statements := make([]base.Statement, len(targets))
+	for i, target := range targets {
+		executor := e.executor(target)
+		if err := executor.prepare(); err != nil {
+			return nil, err
+		}
+		s, args := build(executor.builder.(base.SelectBuilder))
+		executor.log(s, args...)
+		statements[i] = base.Statement{Sql: s, Args: args}
+	}
+	return statements, nil
+}
+
+// fanOut 并发在各分片执行
+func fanOut(targets []ShardTarget, fn func(i int, target ShardTarget) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(targets))
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(i, target); err != nil {
+				errs[i] = fmt.Errorf("query on %s failed: %w", target.Table, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+type shardOrder struct {
+	index int
+	desc  bool
+}
+
+// parseShardOrders 将 ORDER BY 列解析为结构体字段，用于合并各分片结果
+func parseShardOrders[T any](orderBy []string) ([]shardOrder, error) {
+	if len(orderBy) == 0 {
+		return nil, nil
+	}
+	metas := base.GetFieldMetas(reflect.TypeOf((*T)(nil)).Elem())
+	orders := make([]shardOrder, 0, len(orderBy))
+	for _, item := range orderBy {
+		fields := strings.Fields(item)
+		if len(fields) == 0 {
+			continue
+		}
+		column := fields[0]
+		if i := strings.LastIndex(column, "."); i >= 0 {
+			column = column[i+1:]
+		}
+		order := shardOrder{index: -1, desc: len(fields) > 1 && strings.EqualFold(fields[1], "DESC")}
+		for _, meta := range metas {
+			if strings.EqualFold(meta.Column, column) {
+				order.index = meta.Index
+				break
+			}
+		}
+		if order.index == -1 {
+			return nil, fmt.Errorf("order column %s not found in result model", fields[0])
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func lessByOrders(a, b reflect.Value, orders []shardOrder) bool {
+	for _, order := range orders {
+		c := compareValues(fieldValue(a.Field(order.index)), fieldValue(b.Field(order.index)))
+		if c == 0 {
+			continue
+		}
+		if order.desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// compareValues 比较两个字段值，nil 小于任何值
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if x, ok := shardInt(a); ok {
+		if y, ok := shardInt(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	switch x := a.(type) {
+	case float32:
+		if y, ok := b.(float32); ok {
+			return cmp.Compare(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			if x == y {
+				return 0
+			}
+			if !x {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
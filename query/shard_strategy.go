@@ -0,0 +1,165 @@
+package sql
+
+import (
+	"fmt"
+	"hash/crc32"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ShardStrategy 分片策略，将分片键的值映射为分片序号
+type ShardStrategy interface {
+	// Shard 返回值对应的分片序号，范围为 [0, Count())
+	Shard(value any) (int, error)
+	// Count 返回分片数量
+	Count() int
+}
+
+// ModuloStrategy 取模分片：整数按值取模，字符串等其他类型按 crc32 哈希取模
+type ModuloStrategy struct {
+	count int
+}
+
+// NewModuloStrategy 创建取模分片策略
+func NewModuloStrategy(count int) *ModuloStrategy {
+	if count <= 0 {
+		panic("modulo strategy count must be positive")
+	}
+	return &ModuloStrategy{count: count}
+}
+
+func (s *ModuloStrategy) Shard(value any) (int, error) {
+	if n, ok := shardInt(value); ok {
+		shard := n % int64(s.count)
+		if shard < 0 {
+			shard += int64(s.count)
+		}
+		return int(shard), nil
+	}
+	key, err := shardString(value)
+	if err != nil {
+		return 0, err
+	}
+	return int(crc32.ChecksumIEEE([]byte(key)) % uint32(s.count)), nil
+}
+
+func (s *ModuloStrategy) Count() int {
+	return s.count
+}
+
+// RangeStrategy 范围分片：分片 i 包含 [bounds[i-1], bounds[i]) 内的值，第一个分片没有下限
+type RangeStrategy struct {
+	bounds []int64
+}
+
+// NewRangeStrategy 创建范围分片策略
+//
+// 参数:
+//   - bounds: 各分片的上限（不含），必须递增，超出最后一个上限的值无法路由
+func NewRangeStrategy(bounds ...int64) *RangeStrategy {
+	if len(bounds) == 0 {
+		panic("range strategy must have bounds")
+	}
+	if !sort.SliceIsSorted(bounds, func(i, j int) bool { return bounds[i] < bounds[j] }) {
+		panic("range strategy bounds must be increasing")
+	}
+	return &RangeStrategy{bounds: bounds}
+}
+
+func (s *RangeStrategy) Shard(value any) (int, error) {
+	n, ok := shardInt(value)
+	if !ok {
+		return 0, fmt.Errorf("range strategy shard key must be integer, got %T", value)
+	}
+	shard := sort.Search(len(s.bounds), func(i int) bool { return n < s.bounds[i] })
+	if shard == len(s.bounds) {
+		return 0, fmt.Errorf("range strategy shard key %d out of range", n)
+	}
+	return shard, nil
+}
+
+func (s *RangeStrategy) Count() int {
+	return len(s.bounds)
+}
+
+// ConsistentHashStrategy 一致性哈希分片，每个分片在哈希环上放置 replicas 个虚拟节点
+type ConsistentHashStrategy struct {
+	count  int
+	hashes []uint32
+	shards map[uint32]int
+}
+
+// NewConsistentHashStrategy 创建一致性哈希分片策略
+//
+// 参数:
+//   - count: 分片数量
+//   - replicas: 每个分片的虚拟节点数，小于等于 0 时默认为 100
+func NewConsistentHashStrategy(count int, replicas int) *ConsistentHashStrategy {
+	if count <= 0 {
+		panic("consistent hash strategy count must be positive")
+	}
+	if replicas <= 0 {
+		replicas = 100
+	}
+	s := &ConsistentHashStrategy{count: count, shards: make(map[uint32]int, count*replicas)}
+	for shard := 0; shard < count; shard++ {
+		for i := 0; i < replicas; i++ {
+			hash := crc32.ChecksumIEEE([]byte(strconv.Itoa(shard) + "#" + strconv.Itoa(i)))
+			if _, ok := s.shards[hash]; ok {
+				continue
+			}
+			s.shards[hash] = shard
+			s.hashes = append(s.hashes, hash)
+		}
+	}
+	sort.Slice(s.hashes, func(i, j int) bool { return s.hashes[i] < s.hashes[j] })
+	return s
+}
+
+func (s *ConsistentHashStrategy) Shard(value any) (int, error) {
+	key, err := shardString(value)
+	if err != nil {
+		return 0, err
+	}
+	hash := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(s.hashes), func(i int) bool { return s.hashes[i] >= hash })
+	if i == len(s.hashes) {
+		i = 0
+	}
+	return s.shards[s.hashes[i]], nil
+}
+
+func (s *ConsistentHashStrategy) Count() int {
+	return s.count
+}
+
+// shardInt 将整数类型的分片键转换为 int64
+func shardInt(value any) (int64, bool) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+// shardString 将分片键转换为用于哈希的字符串
+func shardString(value any) (string, error) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "", fmt.Errorf("shard key must not be nil")
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "", fmt.Errorf("shard key must not be nil")
+	}
+	return fmt.Sprint(rv.Interface()), nil
+}
@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	base2 "github.com/Cooooing/cutil/base"
+	"github.com/Cooooing/cutil/query/base"
+)
+
+// ErrTenantMissing 租户模型执行时上下文中没有租户
+var ErrTenantMissing = errors.New("tenant id not found in context")
+
+type (
+	tenantKey       struct{}
+	tenantBypassKey struct{}
+)
+
+// WithTenant 将租户 ID 写入上下文
+func WithTenant(ctx context.Context, tenantId any) context.Context {
+	return base2.SetContextValue(ctx, tenantKey{}, tenantId)
+}
+
+// WithoutTenant 显式跳过租户限定（如后台管理任务），慎用
+func WithoutTenant(ctx context.Context) context.Context {
+	return base2.SetContextValue(ctx, tenantBypassKey{}, true)
+}
+
+// TenantFromContext 从上下文中读取租户 ID
+func TenantFromContext(ctx context.Context) (any, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	return base2.GetContextValue[tenantKey, any](ctx, tenantKey{})
+}
+
+// tenantColumn 返回模型中 corm:"tenant" 标记的列名
+func tenantColumn[T any]() (string, bool) {
+	for _, meta := range base.GetFieldMetas(reflect.TypeOf((*T)(nil)).Elem()) {
+		if meta.IsTenant {
+			return meta.Column, true
+		}
+	}
+	return "", false
+}
+
+// applyScope 为租户模型注入租户限定：查询、更新、删除追加 tenant = ?，插入填充租户列。
+// 上下文中没有租户且未显式跳过时返回 ErrTenantMissing，构建器不支持范围限定时返回 base.ErrorBuilderNotSupportScope
+func (e *Executor[T]) applyScope() error {
+	column, ok := tenantColumn[T]()
+	if !ok {
+		return nil
+	}
+	if e.ctx != nil {
+		if bypass, _ := base2.GetContextValue[tenantBypassKey, bool](e.ctx, tenantBypassKey{}); bypass {
+			return nil
+		}
+	}
+	tenantId, ok := TenantFromContext(e.ctx)
+	if !ok {
+		return ErrTenantMissing
+	}
+	scope, ok := e.builder.(base.ScopeBuilder)
+	if !ok {
+		return fmt.Errorf("%w: %T", base.ErrorBuilderNotSupportScope, e.builder)
+	}
+	scope.Scope(column, tenantId)
+	return nil
+}
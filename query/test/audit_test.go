@@ -0,0 +1,132 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	sql2 "github.com/Cooooing/cutil/query"
+	"github.com/Cooooing/cutil/query/base"
+	"github.com/Cooooing/cutil/query/dml"
+	"github.com/Cooooing/cutil/query/dql"
+)
+
+type AuditUser struct {
+	Id   *int    `json:"id" corm:"primaryKey"`
+	Name *string `json:"name"`
+	Age  *int    `json:"age"`
+}
+
+// recordQueryer 记录执行的sql
+type recordQueryer struct {
+	base.Queryer
+	sql  string
+	args []any
+}
+
+func (q *recordQueryer) Exec(query string, args ...any) (sql.Result, error) {
+	q.sql, q.args = query, args
+	return nil, nil
+}
+
+func TestAuditTarget(t *testing.T) {
+	table, alias, where, args := dml.NewUpdate().TableAlias("users", "u").Set("name", "a").
+		Where(dql.NewCondition().Eq("u.id", 1)).(base.TargetBuilder).Target()
+	if table != "users" || alias != "u" || where != "u.id = ?" || !reflect.DeepEqual(args, []any{1}) {
+		t.Errorf("Target() = %s, %s, %s, %+v", table, alias, where, args)
+	}
+
+	builder := dml.NewDelete()
+	builder.From("users")
+	builder.Scope("tenant_id", 7)
+	_, _, where, args = builder.Target()
+	if where != "tenant_id = ?" || !reflect.DeepEqual(args, []any{7}) {
+		t.Errorf("Target() where = %s, %+v", where, args)
+	}
+}
+
+func TestTableAuditSink(t *testing.T) {
+	q := &recordQueryer{}
+	records := []*sql2.AuditRecord{{
+		Actor:      42,
+		Table:      "users",
+		Action:     sql2.AuditActionUpdate,
+		PrimaryKey: map[string]any{"id": 1},
+		Changes:    map[string]sql2.AuditChange{"name": {Old: "Alice", New: "Alicia"}},
+	}}
+	if err := sql2.NewTableAuditSink("audit_log").Write(context.Background(), q, records); err != nil {
+		t.Fatal(err)
+	}
+	wantSql := "INSERT INTO audit_log (actor, table_name, action, primary_key, changes, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+	if q.sql != wantSql {
+		t.Errorf("Write() sql = %s, want %s", q.sql, wantSql)
+	}
+	wantArgs := []any{"42", "users", "update", `{"id":1}`, `{"name":{"old":"Alice","new":"Alicia"}}`, records[0].Time}
+	if !reflect.DeepEqual(q.args, wantArgs) {
+		t.Errorf("Write() args = %+v, want %+v", q.args, wantArgs)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	Init(t)
+	var records []*sql2.AuditRecord
+	sink := sql2.AuditSinkFunc(func(ctx context.Context, conn base.Queryer, r []*sql2.AuditRecord) error {
+		records = append(records, r...)
+		return nil
+	})
+	ctx := sql2.WithActor(context.Background(), "admin")
+
+	_, err := sql2.WithExecutor[AuditUser](DB, dml.NewUpdate().Table("users").Set("age", 26).
+		Where(dql.NewCondition().Eq("name", "Alice"))).WithContext(ctx).Audit(sink).Debug().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if record.Actor != "admin" || record.Action != sql2.AuditActionUpdate {
+			t.Errorf("record = %+v", record)
+		}
+		for column := range record.Changes {
+			if column != "age" {
+				t.Errorf("unexpected changed column %s", column)
+			}
+		}
+	}
+}
+
+// countQueryer 统计执行的语句数与最大参数数
+type countQueryer struct {
+	base.Queryer
+	statements int
+	maxArgs    int
+}
+
+func (q *countQueryer) Exec(_ string, args ...any) (sql.Result, error) {
+	q.statements++
+	q.maxArgs = max(q.maxArgs, len(args))
+	return nil, nil
+}
+
+func TestTableAuditSinkBatch(t *testing.T) {
+	records := make([]*sql2.AuditRecord, base.MaxParams/6+1)
+	for i := range records {
+		records[i] = &sql2.AuditRecord{Table: "users", Action: sql2.AuditActionDelete, PrimaryKey: map[string]any{"id": i}}
+	}
+	q := &countQueryer{}
+	if err := sql2.NewTableAuditSink("audit_log").Write(context.Background(), q, records); err != nil {
+		t.Fatal(err)
+	}
+	if q.statements != 2 || q.maxArgs > base.MaxParams {
+		t.Errorf("Write() executed %d statements with at most %d args", q.statements, q.maxArgs)
+	}
+}
+
+func TestAuditBulkUpdate(t *testing.T) {
+	name := "a"
+	builder := dml.NewBulkUpdate().Table("users").Rows([]*AuditUser{{Id: new(int), Name: &name}})
+	_, err := sql2.WithExecutor[AuditUser](nil, builder).Audit(sql2.NewTableAuditSink("audit_log")).Exec()
+	if !errors.Is(err, sql2.ErrAuditNotSupported) {
+		t.Errorf("Exec() bulk update with audit = %v, want %v", err, sql2.ErrAuditNotSupported)
+	}
+}
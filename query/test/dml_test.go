@@ -2,10 +2,13 @@ package test
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/Cooooing/cutil/base/logger"
 	"github.com/Cooooing/cutil/query"
+	"github.com/Cooooing/cutil/query/base"
 	"github.com/Cooooing/cutil/query/dml"
 )
 
@@ -36,3 +39,65 @@ func TestInsert(t *testing.T) {
 	logger.Info("users: %s", string(bytes))
 
 }
+
+type BulkUser struct {
+	Id   int    `json:"id" corm:"primaryKey"`
+	Name string `json:"name"`
+	Age  *int   `json:"age"`
+}
+
+func TestBulkUpdate(t *testing.T) {
+	age := 30
+	users := []BulkUser{{Id: 1, Name: "Alice", Age: &age}, {Id: 2, Name: "Bob"}, {Id: 3, Name: "Carol", Age: &age}}
+
+	s, args := dml.NewBulkUpdate().Table("users").Rows(users[:2]).Dialect(base.DialectMySQL).Build()
+	wantSql := "UPDATE users SET name = CASE id WHEN ? THEN ? WHEN ? THEN ? END, age = CASE id WHEN ? THEN ? WHEN ? THEN ? END WHERE id IN (?, ?)"
+	if s != wantSql {
+		t.Errorf("Build() = %s, want %s", s, wantSql)
+	}
+	wantArgs := []any{1, "Alice", 2, "Bob", 1, &age, 2, (*int)(nil), 1, 2}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("Build() args = %+v, want %+v", args, wantArgs)
+	}
+
+	rows := []map[string]any{{"id": 1, "name": "Alice", "age": 26}, {"id": 2, "name": "Bob", "age": 31}}
+	s, args = dml.NewBulkUpdate().Table("users").Key("id").Rows(rows).Dialect(base.DialectPostgres).Build()
+	wantSql = "UPDATE users SET age = v.age, name = v.name FROM (VALUES (CAST(? AS bigint), CAST(? AS bigint), COALESCE(?, (NULL::users).name)), (?, ?, ?)) AS v(id, age, name) WHERE users.id = v.id"
+	if s != wantSql {
+		t.Errorf("Build() = %s, want %s", s, wantSql)
+	}
+	wantArgs = []any{1, 26, "Alice", 2, 31, "Bob"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("Build() args = %+v, want %+v", args, wantArgs)
+	}
+
+	// 执行时占位符改写为 $n
+	s, _, err := sql.WithExecutor[BulkUser](nil, dml.NewBulkUpdate().Table("users").Key("id").Rows(rows).Dialect(base.DialectPostgres)).Dialect(base.DialectPostgres).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSql = "UPDATE users SET age = v.age, name = v.name FROM (VALUES (CAST($1 AS bigint), CAST($2 AS bigint), COALESCE($3, (NULL::users).name)), ($4, $5, $6)) AS v(id, age, name) WHERE users.id = v.id"
+	if s != wantSql {
+		t.Errorf("Executor.Build() = %s, want %s", s, wantSql)
+	}
+
+	chunks := dml.NewBulkUpdate().Table("users").Rows(users).Columns("name").ChunkSize(2).Dialect(base.DialectMySQL).BuildChunks()
+	if len(chunks) != 2 {
+		t.Fatalf("BuildChunks() len = %d, want 2", len(chunks))
+	}
+	if want := "UPDATE users SET name = CASE id WHEN ? THEN ? END WHERE id IN (?)"; chunks[1].Sql != want {
+		t.Errorf("BuildChunks()[1] = %s, want %s", chunks[1].Sql, want)
+	}
+
+	// 单行参数超过上限时每行单独成一条语句
+	wide := make([]map[string]any, 2)
+	for i := range wide {
+		wide[i] = map[string]any{"id": i}
+		for j := 0; j < base.MaxParams/2+1; j++ {
+			wide[i][fmt.Sprintf("c%d", j)] = j
+		}
+	}
+	if chunks := dml.NewBulkUpdate().Table("users").Key("id").Rows(wide).Dialect(base.DialectMySQL).BuildChunks(); len(chunks) != 2 {
+		t.Errorf("BuildChunks() wide rows len = %d, want 2", len(chunks))
+	}
+}
@@ -2,10 +2,12 @@ package test
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/Cooooing/cutil/base/logger"
 	"github.com/Cooooing/cutil/query"
+	"github.com/Cooooing/cutil/query/base"
 	"github.com/Cooooing/cutil/query/dql"
 )
 
@@ -122,3 +124,292 @@ func TestNestedSelect(t *testing.T) {
 	bytes, _ = json.Marshal(res)
 	logger.Info("users: %s", string(bytes))
 }
+
+func TestResultShapeSelect(t *testing.T) {
+	Init(t)
+	builder := func() *sql.Executor[User] {
+		return sql.WithExecutor[User](
+			DB,
+			dql.NewSelect().
+				Columns("id", "name", "age", "email", "created_at").
+				From("users").
+				Where(dql.NewCondition().Gt("age", 20)),
+		).Debug()
+	}
+
+	exists, err := builder().Exists()
+	if err != nil {
+		t.Error(err)
+	}
+	logger.Info("exists: %v", exists)
+
+	names, err := sql.Pluck[User, string](builder(), "name")
+	if err != nil {
+		t.Error(err)
+	}
+	logger.Info("names: %+v", names)
+
+	maxAge, err := sql.Scalar[User, int](sql.WithExecutor[User](
+		DB,
+		dql.NewSelect().Columns("MAX(age)").From("users"),
+	).Debug())
+	if err != nil {
+		t.Error(err)
+	}
+	logger.Info("max age: %d", maxAge)
+
+	byId, err := sql.ToMap(builder(), func(u *User) int { return *u.Id })
+	if err != nil {
+		t.Error(err)
+	}
+	logger.Info("users by id: %+v", len(byId))
+
+	byAge, err := sql.GroupInto(builder(), func(u *User) int { return *u.Age })
+	if err != nil {
+		t.Error(err)
+	}
+	logger.Info("users by age: %+v", len(byAge))
+}
+
+func TestBuildCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder base.SelectBuilder
+		limit   int
+		want    string
+	}{
+		{
+			name: "rewrite",
+			builder: dql.NewSelect().Columns("id", "name").From("users").
+				Where(dql.NewCondition().Gt("age", 20)).OrderBy("id").Limit(10),
+			limit: -1,
+			want:  "SELECT COUNT(*) AS total FROM users WHERE age > ?",
+		},
+		{
+			name: "rewrite with limit",
+			builder: dql.NewSelect().Columns("id", "name").From("users").
+				Where(dql.NewCondition().Gt("age", 20)).OrderBy("id"),
+			limit: 101,
+			want:  "SELECT COUNT(*) AS total FROM (SELECT 1 FROM users WHERE age > ? LIMIT 101) AS t",
+		},
+		{
+			name:    "group by",
+			builder: dql.NewSelect().Columns("age", "COUNT(*)").From("users").GroupBy("age").OrderBy("age"),
+			limit:   -1,
+			want:    "SELECT COUNT(*) AS total FROM (SELECT age, COUNT(*) FROM users GROUP BY age) AS t",
+		},
+		{
+			name:    "distinct",
+			builder: dql.NewSelect().Columns("DISTINCT name").From("users"),
+			limit:   -1,
+			want:    "SELECT COUNT(*) AS total FROM (SELECT DISTINCT name FROM users) AS t",
+		},
+		{
+			name:    "aggregate without group by",
+			builder: dql.NewSelect().Columns("MAX(age)").From("users").Where(dql.NewCondition().Gt("age", 20)),
+			limit:   -1,
+			want:    "SELECT COUNT(*) AS total FROM (SELECT MAX(age) FROM users WHERE age > ?) AS t",
+		},
+		{
+			name:    "window function",
+			builder: dql.NewSelect().Columns("id", "ROW_NUMBER() OVER (ORDER BY age) AS rn").From("users"),
+			limit:   10,
+			want:    "SELECT COUNT(*) AS total FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY age) AS rn FROM users LIMIT 10) AS t",
+		},
+		{
+			name:    "column name containing aggregate word",
+			builder: dql.NewSelect().Columns("max_age", "count").From("users"),
+			limit:   -1,
+			want:    "SELECT COUNT(*) AS total FROM users",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.builder.BuildCount(tt.limit)
+			if got != tt.want {
+				t.Errorf("BuildCount() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildExists(t *testing.T) {
+	builder := dql.NewSelect().Columns("id", "name").From("users").
+		Where(dql.NewCondition().Gt("age", 20)).OrderBy("id").ForUpdate()
+	got, args := builder.BuildExists()
+	if want := "SELECT 1 FROM users WHERE age > ? LIMIT 1"; got != want {
+		t.Errorf("BuildExists() = %s, want %s", got, want)
+	}
+	if len(args) != 1 || args[0] != 20 {
+		t.Errorf("BuildExists() args = %v", args)
+	}
+}
+
+func TestLockSelect(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder base.SelectBuilder
+		want    string
+	}{
+		{
+			name:    "for update skip locked",
+			builder: dql.NewSelect().From("jobs").Where(dql.NewCondition().Eq("status", 0)).Limit(10).ForUpdate().SkipLocked(),
+			want:    "SELECT * FROM jobs WHERE status = ? LIMIT 10 FOR UPDATE SKIP LOCKED",
+		},
+		{
+			name:    "mysql for share",
+			builder: dql.NewSelect().From("jobs").ForShare().Dialect(base.DialectMySQL),
+			want:    "SELECT * FROM jobs LOCK IN SHARE MODE",
+		},
+		{
+			name:    "postgres for share nowait",
+			builder: dql.NewSelect().From("jobs").ForShare().NoWait().Dialect(base.DialectPostgres),
+			want:    "SELECT * FROM jobs FOR SHARE NOWAIT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.builder.GetSql(); got != tt.want {
+				t.Errorf("Build() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubQuerySelect(t *testing.T) {
+	/*
+		SELECT u.id, (SELECT COUNT(*) FROM posts AS p WHERE p.user_id = u.id AND p.status = ?) AS post_count
+		FROM (SELECT * FROM users WHERE age > ?) AS u
+		WHERE u.id IN (SELECT user_id FROM posts WHERE title LIKE ?)
+		  AND u.age > (SELECT AVG(age) FROM users WHERE email IS NOT NULL AND id <> ?)
+	*/
+	builder := dql.NewSelect().
+		Columns("u.id").
+		ColumnSelect(dql.NewSelect().Columns("COUNT(*)").FromAlias("posts", "p").
+			Where(dql.NewCondition().On("p.user_id", "u.id").Eq("p.status", 1)), "post_count").
+		FromSelect(dql.NewSelect().From("users").Where(dql.NewCondition().Gt("age", 20)), "u").
+		Where(dql.NewCondition().
+			InSelect("u.id", dql.NewSelect().Columns("user_id").From("posts").Where(dql.NewCondition().LikeRight("title", "go"))).
+			Gt("u.age", dql.NewSelect().Columns("AVG(age)").From("users").Where(dql.NewCondition().IsNotNull("email").Ne("id", 3))),
+		)
+
+	wantSql := "SELECT u.id, (SELECT COUNT(*) FROM posts AS p WHERE p.user_id = u.id AND p.status = ?) AS post_count " +
+		"FROM (SELECT * FROM users WHERE age > ?) AS u " +
+		"WHERE u.id IN (SELECT user_id FROM posts WHERE title LIKE ?) " +
+		"AND u.age > (SELECT AVG(age) FROM users WHERE email IS NOT NULL AND id <> ?)"
+	wantArgs := []any{1, 20, "go%", 3}
+
+	s, args := builder.Build()
+	if s != wantSql {
+		t.Errorf("Build() = %s, want %s", s, wantSql)
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("Build() args = %+v, want %+v", args, wantArgs)
+	}
+}
+
+func TestJsonMatchCondition(t *testing.T) {
+	mysql := func() base.ConditionBuilder { return dql.NewCondition().Dialect(base.DialectMySQL) }
+	postgres := func() base.ConditionBuilder { return dql.NewCondition().Dialect(base.DialectPostgres) }
+	tests := []struct {
+		name     string
+		builder  base.ConditionBuilder
+		wantSql  string
+		wantArgs []any
+	}{
+		{"mysql json eq", mysql().JsonEq("attrs", "$.color", "red"), "attrs->>'$.color' = ?", []any{"red"}},
+		{"postgres json eq", postgres().JsonEq("attrs", "color", "red"), "attrs->>'color' = ?", []any{"red"}},
+		{"postgres json eq nested", postgres().JsonEq("attrs", "size.width", 10), "attrs #>> '{size,width}' = ?", []any{10}},
+		{"mysql json contains", mysql().JsonContains("tags", "", []string{"go"}), "JSON_CONTAINS(tags, ?)", []any{`["go"]`}},
+		{"mysql json contains path", mysql().JsonContains("attrs", "tags", "go"), "JSON_CONTAINS(attrs, ?, '$.tags')", []any{`"go"`}},
+		{"postgres json contains", postgres().JsonContains("attrs", "", map[string]any{"color": "red"}), "attrs @> CAST(? AS jsonb)", []any{`{"color":"red"}`}},
+		{"mysql json has key", mysql().JsonHasKey("attrs", "size.width"), "JSON_CONTAINS_PATH(attrs, 'one', '$.size.width')", nil},
+		{"postgres json has key", postgres().JsonHasKey("attrs", "size.width"), "attrs #> '{size,width}' IS NOT NULL", nil},
+		{"mysql match", mysql().Match([]string{"title", "content"}, "golang", base.MatchNatural), "MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)", []any{"golang"}},
+		{"mysql match phrase", mysql().Match([]string{"title"}, "hello world", base.MatchPhrase), "MATCH(title) AGAINST (? IN BOOLEAN MODE)", []any{`"hello world"`}},
+		{"postgres match", postgres().Match([]string{"title", "content"}, "golang", base.MatchNatural), "to_tsvector(concat_ws(' ', title, content)) @@ plainto_tsquery(?)", []any{"golang"}},
+		{"postgres match boolean", postgres().Match([]string{"title"}, "go & sql", base.MatchBoolean), "to_tsvector(title) @@ to_tsquery(?)", []any{"go & sql"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, args := tt.builder.Build()
+			if s != tt.wantSql {
+				t.Errorf("Build() = %s, want %s", s, tt.wantSql)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Build() args = %+v, want %+v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestJsonMatchConditionError(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder base.ConditionBuilder
+	}{
+		{"json eq empty path", dql.NewCondition().JsonEq("attrs", "", "red")},
+		{"json eq invalid path", dql.NewCondition().JsonEq("attrs", "a'b", "red")},
+		{"json contains marshal", dql.NewCondition().JsonContains("attrs", "", func() {})},
+		{"json has key empty path", dql.NewCondition().JsonHasKey("attrs", "$")},
+		{"match no columns", dql.NewCondition().Match(nil, "golang", base.MatchNatural)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := base.BuildError(tt.builder); err == nil {
+				t.Error("Err() = nil, want error")
+			}
+			builder := dql.NewSelect().From("products").Where(tt.builder.Eq("id", 1))
+			if s, _ := builder.Build(); s != "SELECT * FROM products WHERE id = ?" {
+				t.Errorf("Build() = %s, want invalid condition skipped", s)
+			}
+			if _, _, err := sql.WithExecutor[map[string]any](nil, builder).Build(); err == nil {
+				t.Error("executor Build() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestJsonMatchConditionRebind(t *testing.T) {
+	cond := dql.NewCondition().Dialect(base.DialectPostgres).
+		JsonEq("attrs", "size.width", 10).
+		JsonContains("attrs", "", map[string]any{"color": "red"}).
+		Match([]string{"title"}, "golang", base.MatchNatural)
+	builder := dql.NewSelect().From("products").Where(cond)
+	s, args, err := sql.WithExecutor[map[string]any](nil, builder).Dialect(base.DialectPostgres).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT * FROM products WHERE attrs #>> '{size,width}' = $1 AND attrs @> CAST($2 AS jsonb) AND to_tsvector(title) @@ plainto_tsquery($3)"
+	if s != want {
+		t.Errorf("Build() = %s, want %s", s, want)
+	}
+	if len(args) != 3 {
+		t.Errorf("Build() args = %+v", args)
+	}
+}
+
+func TestCloneSelect(t *testing.T) {
+	template := dql.NewSelect().From("orders").Where(dql.NewCondition().Eq("status", 1))
+	want := template.GetSql()
+
+	list := template.Clone().OrderByDesc("id").Limit(10)
+	list.Where(dql.NewCondition().Eq("status", 1).Gt("amount", 100))
+	summary := template.Clone().Columns("COUNT(*) AS total", "SUM(amount) AS amount")
+
+	if got := template.GetSql(); got != want {
+		t.Errorf("template changed = %s, want %s", got, want)
+	}
+	if got, wantList := list.GetSql(), "SELECT * FROM orders WHERE status = ? AND amount > ? ORDER BY id DESC LIMIT 10"; got != wantList {
+		t.Errorf("list = %s, want %s", got, wantList)
+	}
+	if got, wantSummary := summary.GetSql(), "SELECT COUNT(*) AS total, SUM(amount) AS amount FROM orders WHERE status = ?"; got != wantSummary {
+		t.Errorf("summary = %s, want %s", got, wantSummary)
+	}
+
+	cond := dql.NewCondition().Eq("a", 1)
+	branch := cond.Clone().Eq("b", 2)
+	if cond.GetSql() != "a = ?" || branch.GetSql() != "a = ? AND b = ?" {
+		t.Errorf("condition clone = %s, %s", cond.GetSql(), branch.GetSql())
+	}
+}
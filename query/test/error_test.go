@@ -0,0 +1,74 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	sql2 "github.com/Cooooing/cutil/query"
+	"github.com/Cooooing/cutil/query/base"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		kind       error
+		constraint string
+	}{
+		{"no rows", sql.ErrNoRows, base.ErrNotFound, ""},
+		{"mysql duplicate", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.c' for key 'users.uk_email'"}, base.ErrDuplicateKey, "users.uk_email"},
+		{"mysql foreign key", &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`test`.`posts`, CONSTRAINT `fk_posts_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"}, base.ErrForeignKeyViolation, "fk_posts_user"},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, base.ErrDeadlock, ""},
+		{"mysql lock timeout", &mysql.MySQLError{Number: 1205}, base.ErrLockTimeout, ""},
+		{"mysql invalid conn", mysql.ErrInvalidConn, base.ErrConnectionLost, ""},
+		{"pq duplicate", &pq.Error{Code: "23505", Constraint: "users_email_key"}, base.ErrDuplicateKey, "users_email_key"},
+		{"pq foreign key", &pq.Error{Code: "23503", Constraint: "posts_user_id_fkey"}, base.ErrForeignKeyViolation, "posts_user_id_fkey"},
+		{"pq deadlock", &pq.Error{Code: "40P01"}, base.ErrDeadlock, ""},
+		{"pq serialization", &pq.Error{Code: "40001"}, base.ErrSerializationFailure, ""},
+		{"pq connection", &pq.Error{Code: "08006"}, base.ErrConnectionLost, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := base.TranslateError(tt.err)
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("TranslateError(%v) = %v, want %v", tt.err, err, tt.kind)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("TranslateError(%v) lost original error", tt.err)
+			}
+			var dbErr *base.DBError
+			if errors.As(err, &dbErr) && dbErr.Constraint != tt.constraint {
+				t.Errorf("TranslateError(%v) constraint = %s, want %s", tt.err, dbErr.Constraint, tt.constraint)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := &sql2.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+
+	attempts := 0
+	err := policy.Do(func() error {
+		attempts++
+		if attempts < 3 {
+			return &mysql.MySQLError{Number: 1213}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Do() = %v after %d attempts, want nil after 3 attempts", err, attempts)
+	}
+
+	attempts = 0
+	err = policy.Do(func() error {
+		attempts++
+		return &mysql.MySQLError{Number: 1062}
+	})
+	if !errors.Is(err, base.ErrDuplicateKey) || attempts != 1 {
+		t.Errorf("Do() = %v after %d attempts, want duplicate key after 1 attempt", err, attempts)
+	}
+}
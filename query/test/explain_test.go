@@ -0,0 +1,71 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Cooooing/cutil/query/base"
+)
+
+func TestParsePlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  base.Dialect
+		analyze  bool
+		raw      string
+		fullScan []string
+		rows     int64
+		indexes  []string
+	}{
+		{
+			name:    "mysql json",
+			dialect: base.DialectMySQL,
+			raw: `{"query_block": {"select_id": 1, "nested_loop": [
+				{"table": {"table_name": "u", "access_type": "ALL", "rows_examined_per_scan": 1000}},
+				{"table": {"table_name": "p", "access_type": "ref", "key": "idx_user_id", "rows_examined_per_scan": 5}}
+			]}}`,
+			fullScan: []string{"u"},
+			rows:     1005,
+			indexes:  []string{"idx_user_id"},
+		},
+		{
+			name:    "mysql analyze tree",
+			dialect: base.DialectMySQL,
+			analyze: true,
+			raw: `-> Nested loop inner join  (cost=3.3 rows=3) (actual time=0.05..0.08 rows=3 loops=1)
+    -> Table scan on u  (cost=0.55 rows=3) (actual time=0.03..0.04 rows=3 loops=1)
+    -> Index lookup on p using idx_user_id (user_id=u.id)  (cost=0.3 rows=1) (actual time=0.01..0.01 rows=1 loops=3)`,
+			fullScan: []string{"u"},
+			rows:     4,
+			indexes:  []string{"idx_user_id"},
+		},
+		{
+			name:    "postgres json",
+			dialect: base.DialectPostgres,
+			raw: `[{"Plan": {"Node Type": "Hash Join", "Plan Rows": 10, "Plans": [
+				{"Node Type": "Seq Scan", "Relation Name": "users", "Plan Rows": 2000},
+				{"Node Type": "Index Scan", "Relation Name": "posts", "Index Name": "posts_user_id_idx", "Plan Rows": 10}
+			]}}]`,
+			fullScan: []string{"users"},
+			rows:     2010,
+			indexes:  []string{"posts_user_id_idx"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := base.ParsePlan(tt.dialect, tt.raw, tt.analyze)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !plan.FullScan || !reflect.DeepEqual(plan.FullScanTables(), tt.fullScan) {
+				t.Errorf("FullScanTables() = %v, want %v", plan.FullScanTables(), tt.fullScan)
+			}
+			if plan.EstimatedRows != tt.rows {
+				t.Errorf("EstimatedRows = %d, want %d", plan.EstimatedRows, tt.rows)
+			}
+			if !reflect.DeepEqual(plan.Indexes, tt.indexes) {
+				t.Errorf("Indexes = %v, want %v", plan.Indexes, tt.indexes)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	sql2 "github.com/Cooooing/cutil/query"
+	"github.com/Cooooing/cutil/query/base"
+	"github.com/Cooooing/cutil/query/dql"
+)
+
+func TestBindNamed(t *testing.T) {
+	type Param struct {
+		MinAge int    `json:"min_age"`
+		Name   string `corm:"column:user_name"`
+		Ids    []int
+	}
+	param := Param{MinAge: 18, Name: "Alice", Ids: []int{1, 2, 3}}
+
+	tests := []struct {
+		name     string
+		dialect  base.Dialect
+		query    string
+		param    any
+		wantSql  string
+		wantArgs []any
+	}{
+		{
+			name:     "mysql map",
+			dialect:  base.DialectMySQL,
+			query:    "select * from users where age > :age and id in (:ids) and note = ':skip'",
+			param:    map[string]any{"age": 18, "ids": []int{1, 2}},
+			wantSql:  "select * from users where age > ? and id in (?, ?) and note = ':skip'",
+			wantArgs: []any{18, 1, 2},
+		},
+		{
+			name:     "postgres struct",
+			dialect:  base.DialectPostgres,
+			query:    "select * from users where age > @min_age and name = :user_name and id in (:Ids) and created_at::date = now()::date",
+			param:    &param,
+			wantSql:  "select * from users where age > $1 and name = $2 and id in ($3, $4, $5) and created_at::date = now()::date",
+			wantArgs: []any{18, "Alice", 1, 2, 3},
+		},
+		{
+			name:     "empty slice",
+			dialect:  base.DialectMySQL,
+			query:    "select * from users where id in (:ids)",
+			param:    map[string]any{"ids": []int{}},
+			wantSql:  "select * from users where id in (NULL)",
+			wantArgs: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, args, err := base.BindNamed(tt.dialect, tt.query, tt.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s != tt.wantSql {
+				t.Errorf("BindNamed() = %s, want %s", s, tt.wantSql)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("BindNamed() args = %+v, want %+v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	if _, _, err := base.BindNamed(base.DialectMySQL, "select :missing", map[string]any{}); err == nil {
+		t.Error("BindNamed() with missing parameter should return error")
+	}
+}
+
+func TestWhereNamed(t *testing.T) {
+	s, args := dql.NewCondition().
+		Eq("status", 1).
+		Where("age > :age or id in (:ids)", map[string]any{"age": 18, "ids": []int{1, 2}}).
+		Build()
+	wantSql := "status = ? AND (age > ? or id in (?, ?))"
+	if s != wantSql {
+		t.Errorf("Build() = %s, want %s", s, wantSql)
+	}
+	if wantArgs := []any{1, 18, 1, 2}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("Build() args = %+v, want %+v", args, wantArgs)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := "SELECT * FROM users WHERE name = ? AND note <> '?' AND \"a?\" = ? AND age > ?"
+	if got := base.Rebind(base.DialectMySQL, query); got != query {
+		t.Errorf("Rebind() mysql = %s, want unchanged", got)
+	}
+	want := "SELECT * FROM users WHERE name = $1 AND note <> '?' AND \"a?\" = $2 AND age > $3"
+	if got := base.Rebind(base.DialectPostgres, query); got != want {
+		t.Errorf("Rebind() postgres = %s, want %s", got, want)
+	}
+
+	builder := dql.NewSelect().From("users").Where(dql.NewCondition().Eq("name", "a").Gt("age", 18))
+	s, _, err := sql2.WithExecutor[map[string]any](nil, builder).Dialect(base.DialectPostgres).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT * FROM users WHERE name = $1 AND age > $2"; s != want {
+		t.Errorf("Executor.Build() = %s, want %s", s, want)
+	}
+}
+
+func TestWhereNamedError(t *testing.T) {
+	cond := dql.NewCondition().Eq("status", 1).Where("age > :age", map[string]any{})
+	if err := cond.(base.ErrBuilder).Err(); err == nil {
+		t.Fatal("Err() with missing parameter should return error")
+	}
+	builder := dql.NewSelect().From("users").Where(dql.NewCondition().Nested(cond))
+	if _, _, err := sql2.WithExecutor[map[string]any](nil, builder).Build(); err == nil {
+		t.Error("Executor.Build() should return named binding error")
+	}
+}
+
+func TestIsNamedSource(t *testing.T) {
+	type Param struct{ Age int }
+	tests := []struct {
+		param any
+		want  bool
+	}{
+		{map[string]any{"age": 1}, true},
+		{Param{}, true},
+		{&Param{}, true},
+		{time.Now(), false},
+		{sql.NullString{}, false},
+		{18, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := base.IsNamedSource(tt.param); got != tt.want {
+			t.Errorf("IsNamedSource(%T) = %v, want %v", tt.param, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	sql2 "github.com/Cooooing/cutil/query"
+	"github.com/Cooooing/cutil/query/base"
+	"github.com/Cooooing/cutil/query/dml"
+	"github.com/Cooooing/cutil/query/dql"
+)
+
+func TestShardStrategy(t *testing.T) {
+	modulo := sql2.NewModuloStrategy(64)
+	if shard, _ := modulo.Shard(130); shard != 2 {
+		t.Errorf("modulo Shard(130) = %d, want 2", shard)
+	}
+	if shard, _ := modulo.Shard("order-1"); shard < 0 || shard >= 64 {
+		t.Errorf("modulo Shard(string) = %d, out of range", shard)
+	}
+
+	ranges := sql2.NewRangeStrategy(1000, 2000, 3000)
+	if shard, _ := ranges.Shard(1500); shard != 1 {
+		t.Errorf("range Shard(1500) = %d, want 1", shard)
+	}
+	if _, err := ranges.Shard(3000); err == nil {
+		t.Error("range Shard(3000) should be out of range")
+	}
+
+	hash := sql2.NewConsistentHashStrategy(8, 0)
+	first, _ := hash.Shard(12345)
+	second, _ := hash.Shard(12345)
+	if first != second || first < 0 || first >= 8 {
+		t.Errorf("consistent hash Shard() = %d, %d", first, second)
+	}
+}
+
+func TestShardRoute(t *testing.T) {
+	router := sql2.NewShardRouter(nil).Register(sql2.ShardRule{
+		Table:    "orders",
+		Column:   "user_id",
+		Strategy: sql2.NewModuloStrategy(64),
+	})
+
+	builder := dql.NewSelect().FromAlias("orders", "o").Where(dql.NewCondition().Eq("o.user_id", 7).Eq("o.status", 1))
+	targets, err := router.Route(builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0].Table != "orders_07" {
+		t.Fatalf("Route() = %+v, want orders_07", targets)
+	}
+	builder.(base.ShardBuilder).PhysicalTable(targets[0].Table)
+	if got, want := builder.GetSql(), "SELECT * FROM orders_07 AS o WHERE o.user_id = ? AND o.status = ?"; got != want {
+		t.Errorf("Build() = %s, want %s", got, want)
+	}
+
+	targets, _ = router.Route(dql.NewSelect().From("orders").Where(dql.NewCondition().In("user_id", 1, 65, 2)))
+	if len(targets) != 2 || targets[0].Table != "orders_01" || targets[1].Table != "orders_02" {
+		t.Errorf("Route() IN = %+v, want orders_01, orders_02", targets)
+	}
+
+	targets, _ = router.Route(dql.NewSelect().From("orders").Where(dql.NewCondition().Eq("user_id", 1).Or().Eq("user_id", 2)))
+	if len(targets) != 64 {
+		t.Errorf("Route() OR len = %d, want 64", len(targets))
+	}
+
+	targets, _ = router.Route(dql.NewSelect().From("users"))
+	if len(targets) != 1 || targets[0].Table != "" {
+		t.Errorf("Route() unsharded = %+v", targets)
+	}
+
+	insert := dml.NewInsert().Into("orders").Columns("user_id", "amount").Values(3, 10).Values(67, 20)
+	targets, err = router.Route(insert)
+	if err != nil || len(targets) != 1 || targets[0].Table != "orders_03" {
+		t.Errorf("Route() insert = %+v, %v", targets, err)
+	}
+	insert.Values(4, 30)
+	if _, err = router.Route(insert); !errors.Is(err, sql2.ErrShardCrossRows) {
+		t.Errorf("Route() cross insert error = %v, want %v", err, sql2.ErrShardCrossRows)
+	}
+	if _, err = router.Route(dml.NewInsert().Into("orders").Columns("amount").Values(1)); !errors.Is(err, sql2.ErrShardKeyMissing) {
+		t.Errorf("Route() insert without key error = %v, want %v", err, sql2.ErrShardKeyMissing)
+	}
+	// 分片列在值之后追加，行中缺少分片列的值
+	if _, err = router.Route(dml.NewInsert().Into("orders").Columns("amount").Values(1).Columns("user_id")); !errors.Is(err, sql2.ErrShardKeyMissing) {
+		t.Errorf("Route() insert with short row error = %v, want %v", err, sql2.ErrShardKeyMissing)
+	}
+}
+
+// recordConnector 记录连接收到的查询，计数查询返回 0，其余查询返回空结果
+type recordConnector struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (c *recordConnector) Connect(context.Context) (driver.Conn, error) { return &recordConn{c}, nil }
+func (c *recordConnector) Driver() driver.Driver                        { return nil }
+
+type recordConn struct{ c *recordConnector }
+
+func (c *recordConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordConn) Close() error                        { return nil }
+func (c *recordConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c *recordConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.c.mu.Lock()
+	c.c.queries = append(c.c.queries, query)
+	c.c.mu.Unlock()
+	if strings.HasPrefix(query, "SELECT COUNT") {
+		return &recordRows{columns: []string{"count"}, values: []driver.Value{int64(0)}}, nil
+	}
+	return &recordRows{columns: []string{"id"}}, nil
+}
+
+type recordRows struct {
+	columns []string
+	values  []driver.Value
+}
+
+func (r *recordRows) Columns() []string { return r.columns }
+func (r *recordRows) Close() error      { return nil }
+
+func (r *recordRows) Next(dest []driver.Value) error {
+	if r.values == nil {
+		return io.EOF
+	}
+	copy(dest, r.values)
+	r.values = nil
+	return nil
+}
+
+func TestShardFanOutRebind(t *testing.T) {
+	connector := &recordConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	router := sql2.NewShardRouter(db).Register(sql2.ShardRule{
+		Table:    "orders",
+		Column:   "user_id",
+		Strategy: sql2.NewModuloStrategy(2),
+	})
+
+	builder := dql.NewSelect().From("orders").Where(dql.NewCondition().Eq("status", 1))
+	if _, err := sql2.WithShardExecutor[User](router, builder).Dialect(base.DialectPostgres).List(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sql2.WithShardExecutor[User](router, builder).Dialect(base.DialectPostgres).Count(); err != nil {
+		t.Fatal(err)
+	}
+	if len(connector.queries) != 4 {
+		t.Fatalf("executed %d queries, want 4: %v", len(connector.queries), connector.queries)
+	}
+	for _, query := range connector.queries {
+		if strings.Contains(query, "?") || !strings.Contains(query, "$1") {
+			t.Errorf("query %s not rebound for postgres", query)
+		}
+	}
+}
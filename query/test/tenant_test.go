@@ -0,0 +1,120 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	sql2 "github.com/Cooooing/cutil/query"
+	"github.com/Cooooing/cutil/query/base"
+	"github.com/Cooooing/cutil/query/dml"
+	"github.com/Cooooing/cutil/query/dql"
+)
+
+type TenantUser struct {
+	Id       *int    `json:"id" corm:"primaryKey"`
+	TenantId *int    `json:"tenant_id" corm:"column:tenant_id;tenant"`
+	Name     *string `json:"name"`
+}
+
+func TestTenantScope(t *testing.T) {
+	ctx := sql2.WithTenant(context.Background(), 7)
+
+	_, err := sql2.WithExecutor[TenantUser](DB, dql.NewSelect().From("users")).List()
+	if !errors.Is(err, sql2.ErrTenantMissing) {
+		t.Errorf("List() without tenant = %v, want %v", err, sql2.ErrTenantMissing)
+	}
+
+	tests := []struct {
+		name     string
+		builder  base.Builder
+		wantSql  string
+		wantArgs []any
+	}{
+		{
+			name:     "select",
+			builder:  dql.NewSelect().From("users u").LeftJoin("posts", "p", dql.NewCondition().On("u.id", "p.user_id")).Where(dql.NewCondition().Eq("u.name", "a").Or().Eq("u.name", "b")),
+			wantSql:  "SELECT * FROM users u LEFT JOIN posts AS p ON u.id = p.user_id WHERE (u.name = ? OR u.name = ?) AND u.tenant_id = ?",
+			wantArgs: []any{"a", "b", 7},
+		},
+		{
+			name:     "update",
+			builder:  dml.NewUpdate().Table("users").Set("name", "a").Where(dql.NewCondition().Eq("id", 1)),
+			wantSql:  "UPDATE users SET name = ? WHERE (id = ?) AND tenant_id = ?",
+			wantArgs: []any{"a", 1, 7},
+		},
+		{
+			name:     "delete",
+			builder:  dml.NewDelete().From("users"),
+			wantSql:  "DELETE FROM users WHERE tenant_id = ?",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "insert",
+			builder:  dml.NewInsert().Into("users").Columns("name").Values("a").Values("b"),
+			wantSql:  "INSERT INTO users (name, tenant_id) VALUES (?, ?), (?, ?)",
+			wantArgs: []any{"a", 7, "b", 7},
+		},
+		{
+			name:     "insert override",
+			builder:  dml.NewInsert().Into("users").Columns("name", "tenant_id").Values("a", 8),
+			wantSql:  "INSERT INTO users (name, tenant_id) VALUES (?, ?)",
+			wantArgs: []any{"a", 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, args, err := sql2.WithExecutor[TenantUser](DB, tt.builder).WithContext(ctx).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s != tt.wantSql {
+				t.Errorf("Build() = %s, want %s", s, tt.wantSql)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Build() args = %+v, want %+v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	builder := dml.NewDelete().From("users")
+	if s, _, _ := sql2.WithExecutor[TenantUser](DB, builder).WithContext(sql2.WithoutTenant(context.Background())).Build(); s != "DELETE FROM users" {
+		t.Errorf("Build() with bypass = %s, want DELETE FROM users", s)
+	}
+
+	insertSelect := dml.NewInsert().Into("users").Columns("name", "tenant_id").Select(dql.NewSelect().Columns("name", "tenant_id").From("users_bak"))
+	if _, _, err := sql2.WithExecutor[TenantUser](DB, insertSelect).WithContext(ctx).Build(); !errors.Is(err, base.ErrorScopeColumnConflict) {
+		t.Errorf("Build() insert select with tenant column = %v, want %v", err, base.ErrorScopeColumnConflict)
+	}
+	updateTenant := dml.NewUpdate().Table("users").Set("tenant_id", 8).Where(dql.NewCondition().Eq("id", 1))
+	if _, _, err := sql2.WithExecutor[TenantUser](DB, updateTenant).WithContext(ctx).Build(); !errors.Is(err, base.ErrorScopeColumnConflict) {
+		t.Errorf("Build() update set tenant column = %v, want %v", err, base.ErrorScopeColumnConflict)
+	}
+	bulkTenant := dml.NewBulkUpdate().Table("users").Key("id").Columns("name", "tenant_id").Rows(map[string]any{"id": 1, "name": "a", "tenant_id": 8})
+	if _, _, err := sql2.WithExecutor[TenantUser](DB, bulkTenant).WithContext(ctx).Build(); !errors.Is(err, base.ErrorScopeColumnConflict) {
+		t.Errorf("Build() bulk update with tenant column = %v, want %v", err, base.ErrorScopeColumnConflict)
+	}
+	id, tenantId, name := 1, 8, "a"
+	bulkStruct := dml.NewBulkUpdate().Table("users").Key("id").Dialect(base.DialectMySQL).Rows(TenantUser{Id: &id, TenantId: &tenantId, Name: &name})
+	s, args, err := sql2.WithExecutor[TenantUser](DB, bulkStruct).WithContext(ctx).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "UPDATE users SET name = CASE id WHEN ? THEN ? END WHERE (id IN (?)) AND tenant_id = ?"; s != want {
+		t.Errorf("Build() bulk update struct rows = %s, want %s", s, want)
+	}
+	if !reflect.DeepEqual(args, []any{&id, &name, &id, 7}) {
+		t.Errorf("Build() bulk update struct args = %+v", args)
+	}
+	if _, _, err := sql2.WithExecutor[TenantUser](DB, rawBuilder{}).WithContext(ctx).Build(); !errors.Is(err, base.ErrorBuilderNotSupportScope) {
+		t.Errorf("Build() with raw builder = %v, want %v", err, base.ErrorBuilderNotSupportScope)
+	}
+}
+
+// rawBuilder 不支持范围限定的构建器
+type rawBuilder struct{}
+
+func (rawBuilder) GetSql() string         { return "SELECT * FROM users" }
+func (rawBuilder) GetArgs() []any         { return nil }
+func (rawBuilder) Build() (string, []any) { return "SELECT * FROM users", nil }
@@ -65,3 +65,14 @@ VALUES (1, 1),
        (1, 2),
        (2, 3),
        (3, 1);
+
+CREATE TABLE audit_log
+(
+    id          INT PRIMARY KEY AUTO_INCREMENT,
+    actor       VARCHAR(64),
+    table_name  VARCHAR(64),
+    action      VARCHAR(16),
+    primary_key JSON,
+    changes     JSON,
+    created_at  DATETIME
+);
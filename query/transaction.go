@@ -0,0 +1,106 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Cooooing/cutil/base/logger"
+	"github.com/Cooooing/cutil/query/base"
+)
+
+// RetryPolicy 瞬时错误（死锁、序列化失败）重试策略，重试间隔按指数退避
+type RetryPolicy struct {
+	MaxAttempts int           // 最大执行次数（包含首次执行）
+	Backoff     time.Duration // 首次重试前的等待时间，之后每次翻倍
+	MaxBackoff  time.Duration // 最大等待时间，0 表示不限制
+}
+
+// DefaultRetryPolicy 默认重试策略：最多执行 3 次，等待 50ms 起，最长 1s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     50 * time.Millisecond,
+		MaxBackoff:  time.Second,
+	}
+}
+
+// Do 执行 fn，遇到可重试错误时按策略重新执行。返回的错误已分类，可通过 errors.Is 判断
+//
+// 参数:
+//   - fn: 执行函数
+//
+// 返回:
+//   - error: 最后一次执行的错误信息
+func (p *RetryPolicy) Do(fn func() error) error {
+	var err error
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err = base.TranslateError(fn())
+		if err == nil || !base.IsRetryable(err) || attempt >= p.MaxAttempts {
+			return err
+		}
+		logger.Warn("retry after %v, attempt %d/%d: %v", backoff, attempt, p.MaxAttempts, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// Transaction 在事务中执行 fn。fn 返回错误或发生 panic 时回滚，否则提交
+//
+// 参数:
+//   - db: 数据库连接
+//   - fn: 事务函数
+//
+// 返回:
+//   - error: 执行失败的错误信息
+func Transaction(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	return base.TranslateError(transaction(db, fn))
+}
+
+// TransactionWithRetry 在事务中执行 fn，遇到死锁或序列化失败时按重试策略重新执行整个事务。
+// fn 可能被执行多次，不应包含事务外的副作用
+//
+// 参数:
+//   - db: 数据库连接
+//   - policy: 重试策略，为 nil 时使用默认策略
+//   - fn: 事务函数
+//
+// 返回:
+//   - error: 执行失败的错误信息
+func TransactionWithRetry(db *sql.DB, policy *RetryPolicy, fn func(tx *sql.Tx) error) error {
+	if policy == nil {
+		policy = DefaultRetryPolicy()
+	}
+	return policy.Do(func() error {
+		return transaction(db, fn)
+	})
+}
+
+func transaction(db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Error("rollback transaction failed: %w", rbErr)
+			}
+			panic(p)
+		}
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Error("rollback transaction failed: %w", rbErr)
+			}
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			err = fmt.Errorf("commit transaction failed: %w", err)
+		}
+	}()
+	return fn(tx)
+}
@@ -188,6 +188,61 @@ func Reduce[T any, R any](stream Stream[T], identity R,
 	}
 }
 
+// ForEachErr 迭代流中的每个元素执行 action，action 返回错误或流的上下文取消时立即停止并返回该错误
+func ForEachErr[T any](stream Stream[T], action func(T) error) error {
+	switch s := stream.(type) {
+	case *BlockStream[T]:
+		for _, v := range s.elements {
+			if err := s.ctx.Err(); err != nil {
+				return err
+			}
+			if err := action(v); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *NoBlockStream[T]:
+		in := s.initTerminalOp()
+		if s.err != nil {
+			return s.err
+		}
+		for {
+			select {
+			case <-s.ctx.Done():
+				s.close(s.ctx.Err())
+				return s.ctx.Err()
+			case v, ok := <-in:
+				// 上下文取消时生成端可能先关闭通道，此时应返回取消的错误
+				if err := s.ctx.Err(); err != nil {
+					s.close(err)
+					return err
+				}
+				if !ok {
+					s.close(s.err)
+					return s.err
+				}
+				if err := action(v); err != nil {
+					s.close(err)
+					return err
+				}
+			}
+		}
+
+	default:
+		var actionErr error
+		err := stream.ForEach(func(item T) {
+			if actionErr == nil {
+				actionErr = action(item)
+			}
+		})
+		if actionErr != nil {
+			return actionErr
+		}
+		return err
+	}
+}
+
 func GroupBy[T any, K comparable](stream Stream[T], classifier base.Function[T, K]) (map[K][]T, error) {
 	switch s := stream.(type) {
 	case *BlockStream[T]:
@@ -2,6 +2,7 @@ package stream
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -35,3 +36,49 @@ func TestMap(t *testing.T) {
 		})
 	}
 }
+
+func TestForEachErr(t *testing.T) {
+	t.Parallel()
+	errStop := errors.New("stop")
+	tests := []struct {
+		name     string
+		stream   func(ctx context.Context, input ...int) Stream[int]
+		input    []int
+		stopAt   int
+		expected []int
+		err      error
+	}{
+		{name: "block all elements", stream: func(ctx context.Context, input ...int) Stream[int] { return OfBlock(ctx, input...) }, input: []int{1, 2, 3}, expected: []int{1, 2, 3}},
+		{name: "block stop on error", stream: func(ctx context.Context, input ...int) Stream[int] { return OfBlock(ctx, input...) }, input: []int{1, 2, 3}, stopAt: 2, expected: []int{1, 2}, err: errStop},
+		{name: "no block all elements", stream: func(ctx context.Context, input ...int) Stream[int] { return OfNoBlock(ctx, input...) }, input: []int{1, 2, 3}, expected: []int{1, 2, 3}},
+		{name: "no block stop on error", stream: func(ctx context.Context, input ...int) Stream[int] { return OfNoBlock(ctx, input...) }, input: []int{1, 2, 3}, stopAt: 2, expected: []int{1, 2}, err: errStop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []int
+			err := ForEachErr(tt.stream(context.Background(), tt.input...), func(v int) error {
+				result = append(result, v)
+				if v == tt.stopAt {
+					return errStop
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ForEachErr() error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ForEachErr() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for _, s := range []Stream[int]{OfBlock(ctx, 1, 2), OfNoBlock(ctx, 1, 2)} {
+			if err := ForEachErr(s, func(int) error { return nil }); !errors.Is(err, context.Canceled) {
+				t.Errorf("ForEachErr() error = %v, want %v", err, context.Canceled)
+			}
+		}
+	})
+}